@@ -1,9 +1,13 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"sync"
+	"sync/atomic"
 	"time"
+
+	"github.com/whatsacomputertho/go-learn/pkg/limit"
 )
 
 var wg = sync.WaitGroup{}
@@ -190,6 +194,94 @@ func main() {
 	wg.Wait()
 	fmt.Println("")
 
+	/*
+		Semaphores & rate limiting
+
+		A buffered channel of empty structs can only hold as
+		many values as its capacity.  Once it is full, any
+		further sends block until a value is received.  This
+		makes it a semaphore, which limits how many goroutines
+		can do something at once.  Sending into the channel
+		acquires a slot, and receiving from it releases one.
+
+		The limit package builds on this idea.  Its Semaphore
+		lets a goroutine acquire several slots at once, and
+		lets it give up waiting via a context.  Its TokenBucket
+		and LeakyBucket limit how often something can happen
+		rather than how many things can happen at once.  We
+		drive the rate limiters with a fake clock here so that
+		their behavior is the same on every run.
+	*/
+	fmt.Println("#### Semaphores & rate limiting ####")
+
+	// Example 1 - A buffered channel as a semaphore
+	// At most 3 of the 10 goroutines will run at the same time
+	sem := make(chan struct{}, 3)
+	var running, maxRunning atomic.Int32
+	for j := 0; j < 10; j++ {
+		wg.Add(1)
+		go func() {
+			sem <- struct{}{} // Acquire a slot, blocks while full
+			n := running.Add(1)
+			for {
+				m := maxRunning.Load()
+				if n <= m || maxRunning.CompareAndSwap(m, n) {
+					break
+				}
+			}
+			time.Sleep(10 * time.Millisecond)
+			running.Add(-1)
+			<-sem // Release the slot
+			wg.Done()
+		}()
+	}
+	wg.Wait()
+	fmt.Printf("Max goroutines running at once: %v\n", maxRunning.Load())
+
+	// Example 2 - A weighted semaphore
+	// A heavy task acquires 3 of the 4 slots, leaving room for
+	// only one light task
+	weighted := limit.NewSemaphore(4)
+	weighted.Acquire(context.Background(), 3)
+	fmt.Printf("Light task acquired: %v\n", weighted.TryAcquire(1))
+	fmt.Printf("Second light task acquired: %v\n", weighted.TryAcquire(1))
+	weighted.Release(4)
+
+	// Example 3 - A token bucket allows bursts
+	// The bucket holds 3 tokens and refills 1 token per second
+	clock := limit.NewFakeClock(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	tokens := limit.NewTokenBucket(1, 3, clock)
+	for j := 0; j < 4; j++ {
+		fmt.Printf("Token bucket request %v allowed: %v\n", j, tokens.Allow())
+	}
+	clock.Advance(time.Second) // One token is refilled
+	fmt.Printf("Token bucket request after 1s allowed: %v\n", tokens.Allow())
+
+	// Example 4 - A leaky bucket smooths bursts out
+	// One request leaks out per second, no matter how many
+	// arrive at once
+	leaky := limit.NewLeakyBucket(time.Second, 2, clock)
+	for j := 0; j < 3; j++ {
+		fmt.Printf("Leaky bucket request %v allowed: %v\n", j, leaky.Allow())
+	}
+
+	// Example 5 - Queueing in a leaky bucket
+	// Rather than being refused, a waiting request queues until
+	// its turn to leak out
+	start := clock.Now()
+	wg.Add(1)
+	go func() {
+		if err := leaky.Wait(context.Background()); err == nil {
+			fmt.Printf("Leaky bucket request leaked out after %v\n", clock.Now().Sub(start))
+		}
+		wg.Done()
+	}()
+	clock.BlockUntil(1) // Wait for the request to queue up
+	fmt.Printf("Leaky bucket requests queued: %v\n", leaky.Queued())
+	clock.Advance(time.Second)
+	wg.Wait()
+	fmt.Println("")
+
 	/*
 		For loops with channels
 
@@ -0,0 +1,78 @@
+package main
+
+import (
+	"io"
+	"os"
+	"runtime/pprof"
+
+	"github.com/whatsacomputertho/go-learn/pkg/contention"
+)
+
+/*
+Contention
+
+Builds on the goroutines lesson's RWMutex example.  Runs
+readers and writers of a shared counter at a configurable
+ratio against a sync.Mutex, a sync.RWMutex and an atomic
+counter, and reports how long goroutines waited on each.
+*/
+var contentionCmd = &command{
+	name:  "contention",
+	usage: "[-readers n] [-writers n] [-ops n] [-work n] [-mutexprofile file] [-blockprofile file] [-out spec]",
+	short: "compare lock contention between readers and writers",
+}
+
+func init() {
+	contentionCmd.run = runContention
+}
+
+func runContention(args []string) error {
+	fs := newFlagSet(contentionCmd)
+	var cfg contention.Config
+	fs.IntVar(&cfg.Readers, "readers", 8, "goroutines which read the shared counter")
+	fs.IntVar(&cfg.Writers, "writers", 2, "goroutines which increment the shared counter")
+	fs.IntVar(&cfg.Ops, "ops", 100000, "operations performed by each goroutine")
+	fs.IntVar(&cfg.Work, "work", 100, "spin iterations performed while holding the lock")
+	mutexProfile := fs.String("mutexprofile", "", "write the mutex profile to `file` for go tool pprof")
+	blockProfile := fs.String("blockprofile", "", "write the block profile to `file` for go tool pprof")
+	out := outputFlag(fs)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 0 {
+		return errUsage
+	}
+
+	err := withOutput(out, func(w io.Writer) error {
+		results, err := contention.Run(cfg)
+		if err != nil {
+			return err
+		}
+		contention.Fprint(w, cfg, results)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	if err := writeProfile("mutex", *mutexProfile); err != nil {
+		return err
+	}
+	return writeProfile("block", *blockProfile)
+}
+
+// writeProfile writes the named runtime profile to path, if set.
+func writeProfile(name, path string) error {
+	if path == "" {
+		return nil
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := pprof.Lookup(name).WriteTo(f, 0); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
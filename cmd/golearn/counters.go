@@ -0,0 +1,103 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/whatsacomputertho/go-learn/pkg/counter"
+	"github.com/whatsacomputertho/go-learn/pkg/counter/countertest"
+)
+
+/*
+Counters
+
+Builds on the interfaces lesson's Incrementer.  Checks each
+implementation of the extended counter.Incrementer, first alone
+and then shared between goroutines, and reports any increments
+lost.  Only the counter package's tests, run with go test -race,
+show which implementations are safe for concurrent use.
+*/
+var countersCmd = &command{
+	name:  "counters",
+	usage: "[-goroutines n] [-ops n] [-impl name] [-out spec]",
+	short: "check Incrementer implementations for lost increments",
+}
+
+func init() {
+	countersCmd.run = runCounters
+}
+
+// counterImpl names an implementation of counter.Incrementer.
+type counterImpl struct {
+	name string
+	new  func() counter.Incrementer
+}
+
+var counterImpls = []counterImpl{
+	{"IntCounter", func() counter.Incrementer { return new(counter.IntCounter) }},
+	{"AtomicCounter", func() counter.Incrementer { return new(counter.AtomicCounter) }},
+	{"BoundedCounter", func() counter.Incrementer { return counter.NewBoundedCounter(-1<<31, 1<<31-1) }},
+}
+
+func runCounters(args []string) error {
+	fs := newFlagSet(countersCmd)
+	goroutines := fs.Int("goroutines", 8, "goroutines sharing each counter")
+	ops := fs.Int("ops", 100000, "increments performed by each goroutine")
+	only := fs.String("impl", "", "check only the implementation with this `name`")
+	out := outputFlag(fs)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 0 || *goroutines < 1 || *ops < 0 {
+		return errUsage
+	}
+
+	impls := counterImpls
+	if *only != "" {
+		impls = nil
+		for _, impl := range counterImpls {
+			if impl.name == *only {
+				impls = append(impls, impl)
+			}
+		}
+		if len(impls) == 0 {
+			return fmt.Errorf("unknown implementation %q", *only)
+		}
+	}
+
+	failed := 0
+	err := withOutput(out, func(w io.Writer) error {
+		tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(tw, "implementation\tsequential\tconcurrent")
+		for _, impl := range impls {
+			seq := countertest.TestIncrementer(impl.new)
+			conc := countertest.TestConcurrent(impl.new, *goroutines, *ops)
+			if seq != nil || conc != nil {
+				failed++
+			}
+			fmt.Fprintf(tw, "%v\t%v\t%v\n", impl.name, result(seq), result(conc))
+		}
+		tw.Flush()
+		fmt.Fprintln(w, "\nLosing no increments does not prove a counter is race-free.  Run go test -race ./pkg/counter to check.")
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	if failed > 0 {
+		return fmt.Errorf("%v of %v implementation(s) failed", failed, len(impls))
+	}
+	return nil
+}
+
+// result summarises the outcome of a check.
+func result(err error) string {
+	if err == nil {
+		return "ok"
+	}
+	// Keep only the first line of the first error
+	msg, _, _ := strings.Cut(err.Error(), "\n")
+	return "FAIL: " + msg
+}
@@ -0,0 +1,125 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"io/fs"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/whatsacomputertho/go-learn/pkg/dataset"
+)
+
+/*
+Data
+
+Builds on the maps-structs and looping lessons' state
+populations.  Loads a key/value dataset from a CSV or JSON file
+and queries it with the map operations those lessons practise:
+sorted iteration, the largest values, filtering and aggregating.
+A file which does not exist is looked for among the datasets
+shipped with the dataset package, so "states.csv" always works.
+*/
+var dataCmd = &command{
+	name:  "data",
+	usage: "query [-top n] [-sort key|value] [-min n] [-max n] [-match text] [-out spec] <file>",
+	short: "query a key/value dataset loaded from CSV or JSON",
+}
+
+func init() {
+	dataCmd.run = runData
+}
+
+func runData(args []string) error {
+	if len(args) == 0 || args[0] != "query" {
+		return errUsage
+	}
+
+	fs := newFlagSet(dataCmd)
+	top := fs.Int("top", 0, "print only the `n` entries with the largest values")
+	order := fs.String("sort", "key", "sort entries by key or by value, largest first")
+	minValue := fs.Int("min", 0, "keep only entries whose value is at least `n`")
+	maxValue := fs.Int("max", 0, "keep only entries whose value is at most `n`")
+	match := fs.String("match", "", "keep only entries whose key contains `text`, ignoring case")
+	out := outputFlag(fs)
+	files, err := parseInterspersed(fs, args[1:])
+	if err != nil {
+		return err
+	}
+	if len(files) != 1 || *top < 0 || (*order != "key" && *order != "value") {
+		return errUsage
+	}
+	set := make(map[string]bool)
+	fs.Visit(func(f *flag.Flag) { set[f.Name] = true })
+
+	d, err := loadDataset(files[0])
+	if err != nil {
+		return err
+	}
+	d = d.Filter(func(e dataset.Entry) bool {
+		return (!set["min"] || e.Value >= *minValue) &&
+			(!set["max"] || e.Value <= *maxValue) &&
+			strings.Contains(strings.ToLower(e.Key), strings.ToLower(*match))
+	})
+
+	var entries []dataset.Entry
+	switch {
+	case set["top"]:
+		entries = d.Top(*top)
+	case *order == "value":
+		entries = d.ByValue()
+	default:
+		entries = d.Entries()
+	}
+
+	return withOutput(out, func(w io.Writer) error {
+		tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(tw, "key\tvalue")
+		for _, e := range entries {
+			fmt.Fprintf(tw, "%v\t%v\n", e.Key, e.Value)
+		}
+		tw.Flush()
+
+		s := d.Summarize()
+		fmt.Fprintf(w, "\n%v of %v matching entries\n", len(entries), s.Count)
+		if s.Count > 0 {
+			fmt.Fprintf(w, "sum %v, mean %.1f, min %v (%v), max %v (%v)\n",
+				s.Sum, s.Mean, s.Min.Value, s.Min.Key, s.Max.Value, s.Max.Key)
+		}
+		return nil
+	})
+}
+
+// parseInterspersed parses the flags in args, which unlike
+// fs.Parse may follow the positional arguments, as in
+// "states.csv -top 5", and returns the positional arguments.
+func parseInterspersed(fs *flag.FlagSet, args []string) ([]string, error) {
+	var positional []string
+	for {
+		if err := fs.Parse(args); err != nil {
+			return nil, err
+		}
+		if fs.NArg() == 0 {
+			return positional, nil
+		}
+		positional = append(positional, fs.Arg(0))
+		args = fs.Args()[1:]
+	}
+}
+
+// loadDataset loads the named file, or the builtin dataset of
+// that name if no such file exists.
+func loadDataset(name string) (dataset.Dataset, error) {
+	d, err := dataset.Load(name)
+	if errors.Is(err, fs.ErrNotExist) {
+		if builtin, builtinErr := dataset.LoadFS(dataset.Builtin, name); builtinErr == nil {
+			return builtin, nil
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+	return d, nil
+}
@@ -0,0 +1,102 @@
+package main
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// query runs golearn data query with args, writing the report to a
+// file, and returns the report's table of keys and values.
+func query(t *testing.T, args ...string) ([]string, error) {
+	t.Helper()
+	out := filepath.Join(t.TempDir(), "report.txt")
+	args = append([]string{"query"}, args...)
+	if err := runData(append(args, "-out", "file:path="+out)); err != nil {
+		return nil, err
+	}
+	report, err := os.ReadFile(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	table, _, _ := strings.Cut(string(report), "\n\n")
+	var keys []string
+	for _, line := range strings.Split(table, "\n")[1:] {
+		key, _, _ := strings.Cut(line, "  ")
+		keys = append(keys, key)
+	}
+	return keys, nil
+}
+
+func TestDataQuery(t *testing.T) {
+	tests := []struct {
+		name string
+		args []string
+		want []string
+	}{
+		{"SortedByKey", []string{"states.csv"}, []string{"California", "Florida", "Illinois", "New York", "Ohio", "Pennsylvania", "Texas"}},
+		{"SortedByValue", []string{"-sort", "value", "states.json"}, []string{"California", "Texas", "Florida", "New York", "Pennsylvania", "Illinois", "Ohio"}},
+		{"Top", []string{"states.csv", "-top", "2"}, []string{"California", "Texas"}},
+		// -top sorts by value whatever -sort says
+		{"TopSortedByKey", []string{"-top", "2", "-sort", "key", "states.csv"}, []string{"California", "Texas"}},
+		{"MinMax", []string{"-min", "12000000", "-max", "21000000", "states.csv"}, []string{"Florida", "Illinois", "New York", "Pennsylvania"}},
+		{"MinZero", []string{"-min", "0", "states.csv"}, []string{"California", "Florida", "Illinois", "New York", "Ohio", "Pennsylvania", "Texas"}},
+		{"Match", []string{"-match", "NIA", "states.csv"}, []string{"California", "Pennsylvania"}},
+		{"FilterThenTop", []string{"-match", "o", "-top", "1", "states.csv"}, []string{"California"}},
+		{"NoMatches", []string{"-match", "Alaska", "states.csv"}, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := query(t, tt.args...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("query %v = %q, want %q", tt.args, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDataQuerySummary(t *testing.T) {
+	out := filepath.Join(t.TempDir(), "report.txt")
+	if err := runData([]string{"query", "-top", "1", "-max", "13000000", "states.csv", "-out", "file:path=" + out}); err != nil {
+		t.Fatal(err)
+	}
+	report, err := os.ReadFile(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The summary covers every matching entry, not only those printed
+	want := "1 of 3 matching entries\nsum 37218415, mean 12406138.3, min 11614373 (Ohio), max 12802503 (Pennsylvania)\n"
+	if !strings.HasSuffix(string(report), want) {
+		t.Errorf("report ends\n%v\nwant\n%v", report, want)
+	}
+}
+
+func TestDataQueryErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		args []string
+	}{
+		{"NoQuery", []string{}},
+		{"NotQuery", []string{"list", "states.csv"}},
+		{"NoFile", []string{"query"}},
+		{"TwoFiles", []string{"query", "states.csv", "states.json"}},
+		{"NegativeTop", []string{"query", "-top", "-1", "states.csv"}},
+		{"BadSort", []string{"query", "-sort", "size", "states.csv"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := runData(tt.args); !errors.Is(err, errUsage) {
+				t.Errorf("runData(%q) = %v, want %v", tt.args, err, errUsage)
+			}
+		})
+	}
+
+	if _, err := query(t, "missing.csv"); err == nil {
+		t.Error("querying a missing file succeeded")
+	}
+}
@@ -0,0 +1,126 @@
+/*
+golearn is a companion tool for the lessons in this repository.
+
+Each lesson is a standalone program which demonstrates a
+language feature.  The golearn tool gathers the utilities we
+build alongside those lessons behind a single command, so that
+the claims made in the lessons can be checked on the learner's
+own machine and against the learner's own code.
+
+Usage:
+
+	golearn <command> [flags] [arguments]
+
+Run "golearn help" for the list of commands.
+*/
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/whatsacomputertho/go-learn/pkg/registry"
+	_ "github.com/whatsacomputertho/go-learn/pkg/registry/writers"
+)
+
+// A command is a single golearn subcommand.  Each command parses
+// its own flags from the arguments following its name.
+type command struct {
+	name  string
+	usage string
+	short string
+	run   func(args []string) error
+}
+
+// commands lists every golearn subcommand in the order they are
+// printed by "golearn help".
+var commands = []*command{
+	spawnCmd,
+	contentionCmd,
+	vetCmd,
+	countersCmd,
+	methodsetCmd,
+	mockgenCmd,
+	dataCmd,
+	layoutCmd,
+}
+
+// errUsage is returned by a command when it was invoked with
+// invalid arguments.  The command's usage is printed in response.
+var errUsage = errors.New("invalid usage")
+
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+		os.Exit(2)
+	}
+
+	name := os.Args[1]
+	if name == "help" || name == "-h" || name == "--help" {
+		usage()
+		return
+	}
+
+	for _, cmd := range commands {
+		if cmd.name != name {
+			continue
+		}
+		err := cmd.run(os.Args[2:])
+		if errors.Is(err, errUsage) || errors.Is(err, flag.ErrHelp) {
+			fmt.Fprintf(os.Stderr, "usage: golearn %v %v\n", cmd.name, cmd.usage)
+			os.Exit(2)
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "golearn %v: %v\n", cmd.name, err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	fmt.Fprintf(os.Stderr, "golearn: unknown command %q\n", name)
+	usage()
+	os.Exit(2)
+}
+
+func usage() {
+	fmt.Fprintln(os.Stderr, "usage: golearn <command> [flags] [arguments]")
+	fmt.Fprintln(os.Stderr, "")
+	fmt.Fprintln(os.Stderr, "commands:")
+	for _, cmd := range commands {
+		fmt.Fprintf(os.Stderr, "  %-12v %v\n", cmd.name, cmd.short)
+	}
+}
+
+// newFlagSet returns a flag set for the given command which
+// reports errors to the caller rather than exiting.
+func newFlagSet(cmd *command) *flag.FlagSet {
+	fs := flag.NewFlagSet("golearn "+cmd.name, flag.ContinueOnError)
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "usage: golearn %v %v\n", cmd.name, cmd.usage)
+		fs.PrintDefaults()
+	}
+	return fs
+}
+
+// outputFlag defines the -out flag, which selects the writer a
+// command prints its report to from a registry configuration
+// string.
+func outputFlag(fs *flag.FlagSet) *registry.Flag {
+	out := registry.NewFlag("console")
+	fs.Var(out, "out", "write the report to the writer configured by `spec`, one of "+strings.Join(registry.Names(), ", "))
+	return out
+}
+
+// withOutput constructs the writer selected by out, calls write
+// with it, and closes it.
+func withOutput(out *registry.Flag, write func(w io.Writer) error) error {
+	w, err := out.New()
+	if err != nil {
+		return err
+	}
+	return errors.Join(write(w), w.Close())
+}
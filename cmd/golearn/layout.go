@@ -0,0 +1,104 @@
+package main
+
+import (
+	"fmt"
+	"go/types"
+	"io"
+	"runtime"
+	"strings"
+
+	"github.com/whatsacomputertho/go-learn/pkg/layout"
+	"golang.org/x/tools/go/packages"
+)
+
+/*
+Layout
+
+Ties the pointers and maps-structs lessons to the memory behind
+them.  Prints the offset, size, alignment and padding of each
+field of a struct type, as unsafe.Offsetof, unsafe.Sizeof and
+unsafe.Alignof would report them, and suggests an order for the
+fields which needs less padding.
+
+Like methodset, the package is type-checked from source, and
+laid out as the gc compiler would for the chosen architecture.
+*/
+var layoutCmd = &command{
+	name:  "layout",
+	usage: "[-arch name] [-out spec] <package>.<Type> | <package> <Type>",
+	short: "print the memory layout of a struct type and suggest a smaller one",
+}
+
+func init() {
+	layoutCmd.run = runLayout
+}
+
+func runLayout(args []string) error {
+	fs := newFlagSet(layoutCmd)
+	arch := fs.String("arch", runtime.GOARCH, "lay the struct out for the architecture `name`, as in GOARCH")
+	out := outputFlag(fs)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	var pattern, typeName string
+	switch fs.NArg() {
+	case 1:
+		// The type follows the last dot, as package paths may
+		// contain dots of their own
+		i := strings.LastIndex(fs.Arg(0), ".")
+		if i < 0 {
+			return errUsage
+		}
+		pattern, typeName = fs.Arg(0)[:i], fs.Arg(0)[i+1:]
+	case 2:
+		pattern, typeName = fs.Arg(0), fs.Arg(1)
+	default:
+		return errUsage
+	}
+	sizes := types.SizesFor("gc", *arch)
+	if sizes == nil {
+		return fmt.Errorf("unknown architecture %q", *arch)
+	}
+
+	pkgs, err := packages.Load(&packages.Config{Mode: packages.LoadAllSyntax}, pattern)
+	if err != nil {
+		return err
+	}
+	if n := packages.PrintErrors(pkgs); n > 0 {
+		return fmt.Errorf("%v error(s) loading packages", n)
+	}
+	if len(pkgs) != 1 {
+		return fmt.Errorf("%q matched %v packages, want 1", pattern, len(pkgs))
+	}
+	pkg := pkgs[0].Types
+
+	named, err := lookupNamed(pkg, typeName)
+	if err != nil {
+		return err
+	}
+	// Qualify types from other packages by package name only,
+	// as they would be written in pkg
+	qf := func(p *types.Package) string {
+		if p == pkg {
+			return ""
+		}
+		return p.Name()
+	}
+	l, err := layout.FromTypes(named, sizes, qf)
+	if err != nil {
+		return err
+	}
+	return withOutput(out, func(w io.Writer) error {
+		fmt.Fprintf(w, "package %v, GOARCH=%v\n\n", pkg.Path(), *arch)
+		l.Fprint(w)
+
+		suggested := l.Suggest()
+		if suggested.Size >= l.Size {
+			fmt.Fprintln(w, "\nNo order of the fields needs less padding.")
+			return nil
+		}
+		fmt.Fprintf(w, "\nReordering the fields would save %v bytes:\n\n", l.Size-suggested.Size)
+		suggested.Fprint(w)
+		return nil
+	})
+}
@@ -0,0 +1,109 @@
+package main
+
+import (
+	"fmt"
+	"go/types"
+	"io"
+	"strings"
+
+	"github.com/whatsacomputertho/go-learn/pkg/methodset"
+	"golang.org/x/tools/go/packages"
+)
+
+/*
+Methodset
+
+Backs the interfaces lesson's explanation of why a
+BufferedWriterCloser value cannot be assigned to a WriterCloser.
+Lists the method sets of a type T and of *T, reports which
+interfaces in scope each satisfies, and explains exactly which
+methods are missing when one falls short.
+
+Like vet, the package is type-checked from source.
+*/
+var methodsetCmd = &command{
+	name:  "methodset",
+	usage: "[-imports] [-out spec] <package> <Type>",
+	short: "explain the method sets of a type and the interfaces it satisfies",
+}
+
+func init() {
+	methodsetCmd.run = runMethodset
+}
+
+func runMethodset(args []string) error {
+	fs := newFlagSet(methodsetCmd)
+	imports := fs.Bool("imports", false, "also check the interfaces exported by the packages the package imports")
+	out := outputFlag(fs)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 2 {
+		return errUsage
+	}
+	pattern, typeName := fs.Arg(0), fs.Arg(1)
+
+	pkgs, err := packages.Load(&packages.Config{Mode: packages.LoadAllSyntax}, pattern)
+	if err != nil {
+		return err
+	}
+	if n := packages.PrintErrors(pkgs); n > 0 {
+		return fmt.Errorf("%v error(s) loading packages", n)
+	}
+	if len(pkgs) != 1 {
+		return fmt.Errorf("%q matched %v packages, want 1", pattern, len(pkgs))
+	}
+	pkg := pkgs[0].Types
+
+	named, err := lookupNamed(pkg, typeName)
+	if err != nil {
+		return err
+	}
+	if types.IsInterface(named) {
+		return fmt.Errorf("%v is an interface type, whose method set is its methods", typeName)
+	}
+	report := methodset.Analyze(named, methodset.Interfaces(pkg, *imports), pkg)
+	return withOutput(out, func(w io.Writer) error {
+		fmt.Fprintf(w, "package %v\n\n", pkg.Path())
+		report.Fprint(w)
+		return nil
+	})
+}
+
+// lookupNamed finds the named type called name in pkg.  The name
+// may be qualified by the name of a package which pkg imports, as
+// in iox.BufferedWriterCloser, so that types can be checked
+// against the interfaces in scope where they are used.
+func lookupNamed(pkg *types.Package, name string) (*types.Named, error) {
+	scope := pkg.Scope()
+	if qual, rest, ok := strings.Cut(name, "."); ok {
+		scope = nil
+		for _, imp := range pkg.Imports() {
+			if imp.Name() == qual {
+				scope = imp.Scope()
+				break
+			}
+		}
+		if scope == nil {
+			return nil, fmt.Errorf("package %v does not import a package named %v", pkg.Path(), qual)
+		}
+		name = rest
+	}
+
+	obj := scope.Lookup(name)
+	if obj == nil {
+		return nil, fmt.Errorf("no type %v in scope", name)
+	}
+	tn, ok := obj.(*types.TypeName)
+	if !ok {
+		return nil, fmt.Errorf("%v is a %v, not a type", name, strings.TrimPrefix(fmt.Sprintf("%T", obj), "*types."))
+	}
+	named, ok := tn.Type().(*types.Named)
+	if !ok || tn.IsAlias() {
+		return nil, fmt.Errorf("%v is not a defined type", name)
+	}
+	if named.TypeParams().Len() > 0 {
+		return nil, fmt.Errorf("%v is generic, and must be instantiated first", name)
+	}
+	return named, nil
+}
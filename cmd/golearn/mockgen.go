@@ -0,0 +1,67 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/whatsacomputertho/go-learn/pkg/mockgen"
+	"golang.org/x/tools/go/packages"
+)
+
+/*
+Mockgen
+
+Generates recording mocks of interfaces, so that code which
+depends on the interfaces lesson's Writer, Closer, WriterCloser
+and Incrementer, or any other interface in the module, can be
+tested without hand-written fakes.
+
+Like vet, the package is type-checked from source.
+*/
+var mockgenCmd = &command{
+	name:  "mockgen",
+	usage: "[-pkg name] [-o file] <package> <Interface>...",
+	short: "generate recording mocks of interfaces",
+}
+
+func init() {
+	mockgenCmd.run = runMockgen
+}
+
+func runMockgen(args []string) error {
+	fs := newFlagSet(mockgenCmd)
+	pkgName := fs.String("pkg", "", "package `name` of the generated file, by default that of the interfaces' package")
+	output := fs.String("o", "", "write the generated code to `file` rather than standard output")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() < 2 {
+		return errUsage
+	}
+	pattern, names := fs.Arg(0), fs.Args()[1:]
+
+	pkgs, err := packages.Load(&packages.Config{Mode: packages.LoadAllSyntax}, pattern)
+	if err != nil {
+		return err
+	}
+	if n := packages.PrintErrors(pkgs); n > 0 {
+		return fmt.Errorf("%v error(s) loading packages", n)
+	}
+	if len(pkgs) != 1 {
+		return fmt.Errorf("%q matched %v packages, want 1", pattern, len(pkgs))
+	}
+	pkg := pkgs[0].Types
+	if *pkgName == "" {
+		*pkgName = pkg.Name()
+	}
+
+	src, err := mockgen.Generate(pkg, names, *pkgName, "golearn mockgen")
+	if err != nil {
+		return err
+	}
+	if *output == "" {
+		_, err = os.Stdout.Write(src)
+		return err
+	}
+	return os.WriteFile(*output, src, 0o644)
+}
@@ -0,0 +1,63 @@
+package main
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/whatsacomputertho/go-learn/pkg/spawn"
+)
+
+/*
+Spawn
+
+Backs the goroutines lesson's claim that it is not uncommon
+to run 10,000 to 100,000 goroutines at a time.  Spawns the
+requested number of goroutines, optionally pinned to OS
+threads, and reports their memory and latency cost.
+*/
+var spawnCmd = &command{
+	name:  "spawn",
+	usage: "[-n count] [-locked] [-depth n] [-compare] [-out spec]",
+	short: "measure the cost of spawning many goroutines",
+}
+
+func init() {
+	spawnCmd.run = runSpawn
+}
+
+func runSpawn(args []string) error {
+	fs := newFlagSet(spawnCmd)
+	n := fs.Int("n", 10000, "number of goroutines to spawn")
+	locked := fs.Bool("locked", false, "pin every goroutine to its own OS thread")
+	depth := fs.Int("depth", 0, "nested calls made by each goroutine to grow its stack")
+	compare := fs.Bool("compare", false, fmt.Sprintf("also run the same count with the opposite -locked setting, if no more than %v are locked", spawn.MaxLockedGoroutines))
+	out := outputFlag(fs)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 0 {
+		return errUsage
+	}
+
+	configs := []spawn.Config{{N: *n, LockOSThread: *locked, StackDepth: *depth}}
+	// The default count is more than may be locked to OS threads,
+	// so a comparison skips the locked run rather than failing
+	skipLocked := *compare && !*locked && *n > spawn.MaxLockedGoroutines
+	if *compare && !skipLocked {
+		configs = append(configs, spawn.Config{N: *n, LockOSThread: !*locked, StackDepth: *depth})
+	}
+	return withOutput(out, func(w io.Writer) error {
+		if skipLocked {
+			fmt.Fprintf(w, "Skipping the locked run, as at most %v goroutines may be locked to OS threads.  Compare with -n %v or fewer.\n\n",
+				spawn.MaxLockedGoroutines, spawn.MaxLockedGoroutines)
+		}
+		for _, cfg := range configs {
+			report, err := spawn.Run(cfg)
+			if err != nil {
+				return err
+			}
+			report.Fprint(w)
+		}
+		return nil
+	})
+}
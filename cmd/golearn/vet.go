@@ -0,0 +1,116 @@
+package main
+
+import (
+	"fmt"
+	"go/token"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/whatsacomputertho/go-learn/pkg/analysis/lockhandoff"
+	"github.com/whatsacomputertho/go-learn/pkg/analysis/loopvar"
+	"github.com/whatsacomputertho/go-learn/pkg/analysis/sleepsync"
+	"github.com/whatsacomputertho/go-learn/pkg/analysis/wgadd"
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/analysis/checker"
+	"golang.org/x/tools/go/packages"
+)
+
+/*
+Vet
+
+Checks code for the goroutine anti-patterns demonstrated in the
+goroutines lesson.  Each finding links back to the section of
+the lesson which explains it.
+
+Packages and their dependencies are type-checked from source
+rather than from the go command's export data, so that the
+analyzers work with any version of the go command.
+*/
+var vetCmd = &command{
+	name:  "vet",
+	usage: "[-analyzer=false ...] [packages]",
+	short: "report goroutine anti-patterns shown in the lessons",
+}
+
+func init() {
+	vetCmd.run = runVet
+}
+
+// vetAnalyzers are the analyzers run by golearn vet.
+var vetAnalyzers = []*analysis.Analyzer{
+	lockhandoff.Analyzer,
+	loopvar.Analyzer,
+	sleepsync.Analyzer,
+	wgadd.Analyzer,
+}
+
+func runVet(args []string) error {
+	fs := newFlagSet(vetCmd)
+	enabled := make(map[*analysis.Analyzer]*bool)
+	for _, a := range vetAnalyzers {
+		enabled[a] = fs.Bool(a.Name, true, "enable the "+a.Name+" analyzer")
+	}
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	var analyzers []*analysis.Analyzer
+	for _, a := range vetAnalyzers {
+		if *enabled[a] {
+			analyzers = append(analyzers, a)
+		}
+	}
+	patterns := fs.Args()
+	if len(patterns) == 0 {
+		patterns = []string{"."}
+	}
+
+	pkgs, err := packages.Load(&packages.Config{Mode: packages.LoadAllSyntax}, patterns...)
+	if err != nil {
+		return err
+	}
+	if n := packages.PrintErrors(pkgs); n > 0 {
+		return fmt.Errorf("%v error(s) loading packages", n)
+	}
+	graph, err := checker.Analyze(analyzers, pkgs, nil)
+	if err != nil {
+		return err
+	}
+
+	type finding struct {
+		pos token.Position
+		d   analysis.Diagnostic
+	}
+	var findings []finding
+	wd, _ := os.Getwd()
+	for _, act := range graph.Roots {
+		if act.Err != nil {
+			return fmt.Errorf("%v: %v", act, act.Err)
+		}
+		for _, d := range act.Diagnostics {
+			pos := act.Package.Fset.Position(d.Pos)
+			if rel, err := filepath.Rel(wd, pos.Filename); err == nil {
+				pos.Filename = rel
+			}
+			findings = append(findings, finding{pos, d})
+		}
+	}
+	sort.SliceStable(findings, func(i, j int) bool {
+		a, b := findings[i].pos, findings[j].pos
+		if a.Filename != b.Filename {
+			return a.Filename < b.Filename
+		}
+		return a.Offset < b.Offset
+	})
+
+	for _, f := range findings {
+		fmt.Printf("%v: %v\n", f.pos, f.d.Message)
+		if f.d.URL != "" {
+			fmt.Printf("\tsee %v\n", f.d.URL)
+		}
+	}
+	if len(findings) > 0 {
+		return fmt.Errorf("%v issue(s) found", len(findings))
+	}
+	return nil
+}
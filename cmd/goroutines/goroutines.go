@@ -1,16 +1,46 @@
 package main
 
 import (
+	"errors"
 	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
 	"runtime"
+	"strings"
 	"sync"
 	"time"
+
+	"github.com/whatsacomputertho/go-learn/pkg/analysis/loopvar"
+	"github.com/whatsacomputertho/go-learn/pkg/contention"
+	"github.com/whatsacomputertho/go-learn/pkg/spawn"
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/analysis/checker"
+	"golang.org/x/tools/go/packages"
 )
 
 var wg = sync.WaitGroup{}
 var counter = 0
 var m = sync.RWMutex{}
 
+// Used in loop variable capture example, collects one closure per
+// loop iteration and only calls them once the loop has finished
+const loopVarSnippet = `package main
+
+import "fmt"
+
+func main() {
+	var prints []func()
+	for i := 0; i < 3; i++ {
+		prints = append(prints, func() { fmt.Print(i, " ") })
+	}
+	for _, print := range prints {
+		print()
+	}
+	fmt.Println()
+}
+`
+
 func main() {
 	/*
 		GoRoutines
@@ -59,6 +89,69 @@ func main() {
 	time.Sleep(100 * time.Millisecond)
 	fmt.Println("")
 
+	/*
+		Loop variable capture
+
+		Closures capture variables, not values, which is why
+		Example 2 above printed "Goodbye".  The same applies to
+		variables declared by a for loop.
+
+		Before Go 1.22, a for loop declared its variables once,
+		and every iteration shared them.  A closure created in
+		the loop body which was run later, say as a GoRoutine,
+		would usually see the variable's final value.  From Go
+		1.22 on, every iteration declares fresh variables, so
+		each closure sees its own iteration's value.
+
+		Which behavior we get depends on the go directive in the
+		go.mod file of the module being built, not on the version
+		of the go command.  Our go.mod declares go 1.22.1, so we
+		get the new behavior.  We also build the same snippet in
+		two throwaway modules which declare go 1.21 and go 1.22,
+		and run the loopvar analyzer against both of them.
+	*/
+	fmt.Println("#### Loop variable capture ####")
+
+	// Example 1 - Capturing a loop variable in a GoRoutine
+	// Under go 1.22 every GoRoutine gets its own i, so every
+	// value from 0 to 2 is printed in some order
+	for i := 0; i < 3; i++ {
+		wg.Add(1)
+		go func() {
+			fmt.Printf("(%v) Hello from the loop\n", i)
+			wg.Done()
+		}()
+	}
+	wg.Wait()
+
+	// Example 2 - Building the same code with different go
+	// directives.  Under go 1.21 every closure shares one i,
+	// which is 3 by the time they run.
+	for _, goVersion := range []string{"1.21", "1.22"} {
+		dir, err := writeModule(loopVarSnippet, goVersion)
+		if err != nil {
+			fmt.Println(err)
+			continue
+		}
+		out, runErr := runModule(dir)
+
+		// Example 3 - Catching the bug with the loopvar analyzer
+		// It only reports modules targeting go 1.21 or earlier
+		diagnostics, vetErr := vetModule(dir)
+		os.RemoveAll(dir)
+
+		if runErr != nil || vetErr != nil {
+			fmt.Printf("go %v: %v\n", goVersion, errors.Join(runErr, vetErr))
+			continue
+		}
+		fmt.Printf("go %v: %v", goVersion, out)
+		fmt.Printf("go %v: loopvar reported %v issue(s)\n", goVersion, len(diagnostics))
+		for _, d := range diagnostics {
+			fmt.Printf("  %v\n", d)
+		}
+	}
+	fmt.Println("")
+
 	/*
 		WaitGroups
 
@@ -120,6 +213,50 @@ func main() {
 	wg.Wait()
 	fmt.Println("")
 
+	/*
+		Lock contention
+
+		Above, the RWMutex is locked globally which serializes
+		our GoRoutines entirely.  Here we instead lock only
+		around the read or write itself, and measure how long
+		GoRoutines spend waiting on one another when they
+		contend for the same lock.
+
+		We run a read-heavy and a write-heavy workload against a
+		sync.Mutex, a sync.RWMutex, and an atomic counter which
+		takes no lock at all.  The runtime's mutex and block
+		profilers record every time a GoRoutine has to wait.
+
+		A sync.RWMutex allows many readers to hold the lock at
+		once, so it shines when reads dominate.  When writes
+		dominate, its extra bookkeeping can make it slower than
+		a plain sync.Mutex.  Atomic operations avoid waiting
+		altogether, but only apply to simple values.
+
+		Use "golearn contention" to run other ratios of readers
+		to writers, and to write the profiles out for pprof.
+	*/
+	fmt.Println("#### Lock contention ####")
+
+	// Example 1 - Read-heavy workload, 8 readers per 2 writers
+	// Note that "blocked" includes the main GoRoutine waiting
+	// for the workers to finish
+	readHeavy := contention.Config{Readers: 8, Writers: 2, Ops: 50000, Work: 100}
+	if results, err := contention.Run(readHeavy); err != nil {
+		fmt.Println(err)
+	} else {
+		contention.Fprint(os.Stdout, readHeavy, results)
+	}
+
+	// Example 2 - Write-heavy workload, 2 readers per 8 writers
+	writeHeavy := contention.Config{Readers: 2, Writers: 8, Ops: 50000, Work: 100}
+	if results, err := contention.Run(writeHeavy); err != nil {
+		fmt.Println(err)
+	} else {
+		contention.Fprint(os.Stdout, writeHeavy, results)
+	}
+	fmt.Println("")
+
 	/*
 		GOMAXPROCS
 
@@ -139,6 +276,58 @@ func main() {
 
 	// Simply read the current max processes
 	fmt.Printf("GOMAXPROCS: %v\n", runtime.GOMAXPROCS(-1))
+	fmt.Println("")
+
+	/*
+		Scalability
+
+		At the top of this lesson we claimed that it is not
+		uncommon to see 10,000 to 100,000 GoRoutines running at
+		a time, and that OS threads are expensive by comparison.
+		Here we back that claim with numbers measured on this
+		machine.
+
+		We spawn GoRoutines which each park until released, and
+		measure the memory and time it took to spawn them.  We
+		then do the same with GoRoutines which call
+		runtime.LockOSThread, which pins each GoRoutine to its
+		own OS thread.  This approximates the cost of a design
+		which uses one OS thread per task.
+
+		Use "golearn spawn" to run these measurements with other
+		GoRoutine counts and stack depths.
+	*/
+	fmt.Println("#### Scalability ####")
+
+	// Example 1 - Spawning 10,000 and 100,000 GoRoutines
+	// Each GoRoutine starts with a small stack of a few KiB
+	for _, n := range []int{10000, 100000} {
+		report, err := spawn.Run(spawn.Config{N: n})
+		if err != nil {
+			fmt.Println(err)
+			continue
+		}
+		report.Fprint(os.Stdout)
+	}
+
+	// Example 2 - Growing each GoRoutine's stack
+	// Stacks start small and grow as the call depth grows
+	report, err := spawn.Run(spawn.Config{N: 10000, StackDepth: 50})
+	if err != nil {
+		fmt.Println(err)
+	} else {
+		report.Fprint(os.Stdout)
+	}
+
+	// Example 3 - Pinning 1,000 GoRoutines to OS threads
+	// Each one now requires a dedicated OS thread, which costs
+	// far more memory and time to create than a GoRoutine
+	report, err = spawn.Run(spawn.Config{N: 1000, LockOSThread: true})
+	if err != nil {
+		fmt.Println(err)
+	} else {
+		report.Fprint(os.Stdout)
+	}
 }
 
 func sayHello() {
@@ -186,3 +375,60 @@ func incrementMutex() {
 	m.Unlock()
 	wg.Done()
 }
+
+/*
+Loop variable capture
+
+Used in the loop variable capture example, these functions
+write a snippet of code into a throwaway module whose go.mod
+targets the given go version, then run it and analyze it.
+*/
+func writeModule(src, goVersion string) (string, error) {
+	dir, err := os.MkdirTemp("", "loopvar")
+	if err != nil {
+		return "", err
+	}
+	gomod := fmt.Sprintf("module loopvar\n\ngo %v\n", goVersion)
+	if err := os.WriteFile(filepath.Join(dir, "go.mod"), []byte(gomod), 0o644); err != nil {
+		return dir, err
+	}
+	return dir, os.WriteFile(filepath.Join(dir, "main.go"), []byte(src), 0o644)
+}
+
+func runModule(dir string) (string, error) {
+	cmd := exec.Command("go", "run", ".")
+	cmd.Dir = dir
+	// Build with the installed go command rather than downloading
+	// the version named in the go directive
+	cmd.Env = append(os.Environ(), "GOTOOLCHAIN=local")
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		return "", fmt.Errorf("%v: %s", err, out)
+	}
+	return string(out), nil
+}
+
+func vetModule(dir string) ([]string, error) {
+	cfg := &packages.Config{
+		Mode: packages.LoadAllSyntax,
+		Dir:  dir,
+		Env:  append(os.Environ(), "GOTOOLCHAIN=local"),
+	}
+	pkgs, err := packages.Load(cfg, "./...")
+	if err != nil {
+		return nil, err
+	}
+	graph, err := checker.Analyze([]*analysis.Analyzer{loopvar.Analyzer}, pkgs, nil)
+	if err != nil {
+		return nil, err
+	}
+	var diagnostics []string
+	for _, act := range graph.Roots {
+		for _, d := range act.Diagnostics {
+			pos := act.Package.Fset.Position(d.Pos)
+			pos.Filename = strings.TrimPrefix(pos.Filename, dir+string(filepath.Separator))
+			diagnostics = append(diagnostics, fmt.Sprintf("%v: %v", pos, d.Message))
+		}
+	}
+	return diagnostics, nil
+}
@@ -2,10 +2,32 @@ package main
 
 import (
 	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"errors"
 	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"go/types"
 	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/whatsacomputertho/go-learn/pkg/counter"
+	"github.com/whatsacomputertho/go-learn/pkg/counter/countertest"
+	"github.com/whatsacomputertho/go-learn/pkg/iox"
+	"github.com/whatsacomputertho/go-learn/pkg/iox/ioxtest"
+	"github.com/whatsacomputertho/go-learn/pkg/limit"
+	"github.com/whatsacomputertho/go-learn/pkg/pretty"
+	"github.com/whatsacomputertho/go-learn/pkg/registry"
+	_ "github.com/whatsacomputertho/go-learn/pkg/registry/writers"
 )
 
+//go:generate go run ../golearn mockgen -o mocks.go . Writer Closer WriterCloser Incrementer
+
 /*
 Basics of interfaces
 
@@ -29,18 +51,33 @@ type Writer interface {
 /*
 Basics of interfaces
 
-Here we define a ConsoleWriter struct, which is an empty struct
-that implements the Writer interface.  Notice that we implement
-the interface implicitly by simply defining methods on the
-ConsoleWriter struct which share the same signature as the
-Writer interface.
-*/
-type ConsoleWriter struct{}
+A ConsoleWriter is a struct which implements the Writer
+interface by printing each call's data to the console.
+It lives in the iox package so that our tools can share it,
+and it is defined like so.
 
-func (cw ConsoleWriter) Write(data []byte) (int, error) {
-	n, err := fmt.Println(string(data))
-	return n, err
-}
+	type ConsoleWriter struct {
+		w io.Writer // Where to print, standard output if nil
+	}
+
+	func (cw ConsoleWriter) Write(data []byte) (int, error) {
+		w := cw.w
+		if w == nil {
+			w = os.Stdout
+		}
+		return checkedWrite(w, data)
+	}
+
+checkedWrite calls w.Write once, rejects an impossible count,
+and reports a short write without an error as io.ErrShortWrite,
+as the io.Writer contract requires.
+
+Notice that we implement the interface implicitly by simply
+defining methods on the ConsoleWriter struct which share the
+same signature as the Writer interface.  The iox package never
+mentions the Writer interface declared in this file, and yet
+iox.ConsoleWriter implements it.
+*/
 
 /*
 Basics of interfaces
@@ -78,50 +115,231 @@ type WriterCloser interface {
 	Closer
 }
 
-type BufferedWriterCloser struct {
-	buffer *bytes.Buffer
+/*
+Interface composition
+
+A BufferedWriterCloser implements both the Write and Close
+methods, and thus the WriterCloser interface.  It buffers the
+data written to it and passes it on in 8-byte chunks, and its
+Close method flushes the buffer.  Like ConsoleWriter, it lives
+in the iox package, and NewBufferedWriterCloser accepts the
+io.Writer it passes the chunks on to.
+*/
+
+/*
+The io.Writer contract
+
+Used in the io.Writer contract example, printlnWriter is how
+ConsoleWriter was first written.  It adds a newline to every
+write and reports it in the number of bytes written, breaking
+the io.Writer contract.
+
+chunkPrinter prints every write it receives on a line of its
+own, so that we can see how a BufferedWriterCloser splits its
+data into chunks.  Note that it returns len(data) rather than
+the number of bytes it printed, as the count is of bytes taken
+from data.
+*/
+type printlnWriter struct {
+	w io.Writer
+}
+
+func (pw printlnWriter) Write(data []byte) (int, error) {
+	return fmt.Fprintln(pw.w, string(data))
 }
 
-func (bwc *BufferedWriterCloser) Write(data []byte) (int, error) {
-	// Write the bytes to the internal buffer
-	n, err := bwc.buffer.Write(data)
+type chunkPrinter struct{}
+
+func (chunkPrinter) Write(data []byte) (int, error) {
+	fmt.Printf("chunk: %q\n", data)
+	return len(data), nil
+}
+
+/*
+Writer middleware
+
+closeLogger wraps a WriterCloser and prints a message before
+closing it, so that we can see the order in which a stack of
+decorators closes.
+*/
+type closeLogger struct {
+	WriterCloser
+	name string
+}
+
+func (cl closeLogger) Close() error {
+	fmt.Printf("closing %v\n", cl.name)
+	return cl.WriterCloser.Close()
+}
+
+/*
+Registering implementations
+
+chunkFactory implements registry.Factory, constructing
+chunkPrinters.  Registering it under a name in an init function,
+along with the parameters it accepts, makes it available to
+anything which constructs writers from configuration strings,
+alongside the writers registered by the writers package.
+*/
+type chunkFactory struct{}
+
+func (chunkFactory) New(params *registry.Params) (iox.WriterCloser, error) {
+	size, err := params.Int("size", 0)
 	if err != nil {
-		return 0, err
+		return nil, err
 	}
-
-	// If the buffer has at least 8 characters, then it will
-	// write to the console
-	v := make([]byte, 8)
-	for bwc.buffer.Len() > 8 {
-		_, err := bwc.buffer.Read(v)
-		if err != nil {
-			return 0, err
-		}
-		_, err = fmt.Println(string(v))
-		if err != nil {
-			return 0, err
-		}
+	if size <= 0 {
+		return iox.NopCloser(chunkPrinter{}), nil
 	}
-	return n, nil
+	return iox.NewBufferedWriterCloser(chunkPrinter{}, iox.WithPolicy(iox.FlushChunks(size))), nil
 }
 
-func (bwc *BufferedWriterCloser) Close() error {
-	// Flush the buffer
-	for bwc.buffer.Len() > 0 {
-		data := bwc.buffer.Next(8)
-		_, err := fmt.Println(string(data))
-		if err != nil {
-			return err
-		}
-	}
-	return nil
+func init() {
+	registry.Register("chunks", chunkFactory{}, "size")
 }
 
-func NewBufferedWriterCloser() *BufferedWriterCloser {
-	return &BufferedWriterCloser{
-		buffer: bytes.NewBuffer([]byte{}),
-	}
+/*
+Substituting implementations
+
+greet and takeTicket depend only on our interfaces, so they
+can be checked with the recording mocks in mocks.go, which
+"go generate" creates with golearn mockgen, in place of real
+writers and counters.
+*/
+func greet(w WriterCloser, name string) error {
+	_, err := fmt.Fprintf(w, "Hello, %v!\n", name)
+	return errors.Join(err, w.Close())
+}
+
+func takeTicket(inc Incrementer) string {
+	return fmt.Sprintf("ticket #%v", inc.Increment())
+}
+
+/*
+Embedding and interfaces
+
+The maps-structs lesson embeds an Animal in a Bird, and notes
+that embedding does not make a Bird a kind of Animal.  Here we
+extend that taxonomy with methods, so that interfaces can give
+us the polymorphism embedding alone cannot.
+
+A Namer is anything with a name, and a Mover is anything which
+can move.  Neither mentions Animal, Bird, Dog or Fish, and any
+of them satisfies both.
+*/
+type Namer interface {
+	Name() string
+}
+
+type Mover interface {
+	Namer // Embedding an interface in an interface
+	Move() string
+}
+
+/*
+Embedding and interfaces
+
+Animal's methods are promoted to every struct which embeds it,
+just as its fields are.  A Dog uses Animal's Move as is, while
+a Bird and a Fish each declare a Move of their own, which
+shadows the promoted one.  The shadowed method is still there,
+and can be called through the embedded field by name.
+*/
+type Animal struct {
+	name     string
+	speedMPH float64
+}
+
+func (a Animal) Name() string {
+	return a.name
+}
+
+func (a Animal) Move() string {
+	return fmt.Sprintf("%v moves at %v mph", a.name, a.speedMPH)
+}
+
+type Bird struct {
+	Animal
+	WingspanCM float64
+}
+
+func (b Bird) Move() string {
+	return fmt.Sprintf("%v flies on a %vcm wingspan", b.name, b.WingspanCM)
+}
+
+type Dog struct {
+	Animal
+	Breed string
+}
+
+type Fish struct {
+	Animal
+	Fins int
+}
+
+func (f Fish) Move() string {
+	return fmt.Sprintf("%v swims with %v fins, as %v", f.name, f.Fins, f.Animal.Move())
+}
+
+/*
+Embedding and interfaces
+
+Structs can embed interfaces too.  A Pet holds any Namer, and
+the Namer's methods are promoted to the Pet, so that a Pet is a
+Namer itself, whatever it was given.  A Leash holds any Mover,
+and shadows its Move to change what it does.  This is how the
+iox package's middleware wraps one writer in another.
+
+An embedded interface which was never set is nil, so calling
+its methods through the struct panics, just as calling them on
+a nil interface value would.
+*/
+type Pet struct {
+	Namer
+	Owner string
+}
+
+type Leash struct {
+	Mover
+}
+
+func (l Leash) Move() string {
+	return fmt.Sprintf("%v is walked on a leash instead", l.Name())
+}
+
+/*
+Embedding and interfaces
+
+A Mutt embeds both a Dog and a Fish.  Each has a Name promoted
+from its Animal at the same depth, so neither wins, and Name is
+not promoted to the Mutt.  Calling mutt.Name() does not compile,
+and is reported as an ambiguous selector, and a Mutt is neither
+a Namer nor a Mover.  Move is not ambiguous, as the Fish declares
+its own, which is shallower than the Move the Dog gets from its
+Animal, and so wins.
+*/
+type Mutt struct {
+	Dog
+	Fish
+}
+
+// ambiguousSource is a program which selects a field that two
+// embedded structs share, and so does not compile.
+const ambiguousSource = `package main
+
+type Tag struct{ Name string }
+type Collar struct{ Name string }
+
+type Labrador struct {
+	Tag
+	Collar
+}
+
+func main() {
+	var l Labrador
+	println(l.Name)
 }
+`
 
 func main() {
 	/*
@@ -138,10 +356,10 @@ func main() {
 	fmt.Println("#### Basics of interfaces ####")
 
 	// Example of defining a struct as an interface instance
-	var w Writer = ConsoleWriter{}
+	var w Writer = iox.ConsoleWriter{}
 
 	// Example of calling an interface method on that struct
-	w.Write([]byte("Hello, interfaces!"))
+	w.Write([]byte("Hello, interfaces!\n"))
 
 	// Example of defining a primitive as an interface instance
 	myInt := IntCounter(0)
@@ -161,11 +379,11 @@ func main() {
 	fmt.Println("#### Interface composition ####")
 
 	// Initialize a WriterCloser
-	var wc WriterCloser = NewBufferedWriterCloser()
+	var wc WriterCloser = iox.NewBufferedWriterCloser(os.Stdout)
 
 	// Call its Writer & Closer interface methods
 	// These are composed in the WriterCloser interface
-	wc.Write([]byte("Hello, interface composition!"))
+	wc.Write([]byte("Hello, interface composition!\n"))
 	wc.Close()
 	fmt.Println("")
 
@@ -188,7 +406,7 @@ func main() {
 	fmt.Println("#### Type conversion ####")
 
 	// Converting our above WriterCloser to a BufferedWriterCloser
-	bwc := wc.(*BufferedWriterCloser)
+	bwc := wc.(*iox.BufferedWriterCloser)
 	fmt.Println(bwc)
 
 	// Attempting to convert our WriterCloser to an io.Reader
@@ -205,13 +423,288 @@ func main() {
 
 	// Using the empty interface as a middle-man in a safe
 	// type conversion
-	var myObj interface{} = NewBufferedWriterCloser()
+	var myObj interface{} = iox.NewBufferedWriterCloser(os.Stdout)
 	if newWc, ok := myObj.(WriterCloser); ok {
-		newWc.Write([]byte("Hello, interface type conversion!"))
+		newWc.Write([]byte("Hello, interface type conversion!\n"))
 		newWc.Close()
 	}
 	fmt.Println("")
 
+	/*
+		The io.Writer contract
+
+		Any type with a Write([]byte) (int, error) method
+		implements io.Writer, but the standard library expects
+		more of it than its signature says.  Write must return
+		the number of bytes of the argument it wrote, and must
+		return an error whenever that is less than all of them.
+		It must not modify the argument, or hold on to it after
+		returning.
+
+		The ioxtest package checks writers against this contract,
+		much like the testing/iotest package does for readers.
+	*/
+	fmt.Println("#### The io.Writer contract ####")
+
+	// Example 1 - Seeing the chunks a BufferedWriterCloser writes
+	chunked := iox.NewBufferedWriterCloser(chunkPrinter{})
+	n, err := chunked.Write([]byte("Hello, io.Writer contract!"))
+	fmt.Printf("Wrote %v bytes, error: %v\n", n, err)
+	chunked.Close()
+
+	// Example 2 - Checking writers against the contract
+	writers := []struct {
+		name string
+		new  func(io.Writer) io.Writer
+	}{
+		{"ConsoleWriter", func(w io.Writer) io.Writer { return iox.NewConsoleWriter(w) }},
+		{"BufferedWriterCloser", func(w io.Writer) io.Writer { return iox.NewBufferedWriterCloser(w) }},
+		{"printlnWriter", func(w io.Writer) io.Writer { return printlnWriter{w} }},
+	}
+	for _, wr := range writers {
+		if err := ioxtest.TestWriter(wr.new, []byte("Hello, io.Writer contract!")); err != nil {
+			fmt.Printf("%v breaks the contract:\n%v\n", wr.name, err)
+		} else {
+			fmt.Printf("%v honours the contract\n", wr.name)
+		}
+	}
+
+	// Example 3 - Choosing when a BufferedWriterCloser flushes
+	// Here it passes its data on one line at a time
+	lines := iox.NewBufferedWriterCloser(chunkPrinter{}, iox.WithPolicy(iox.FlushLines()))
+	lines.Write([]byte("Hello, lines!\nHello, "))
+	lines.Write([]byte("again!\nGoodbye"))
+	lines.Flush() // Passes on whatever is left, regardless of policy
+
+	// Example 4 - Closing a BufferedWriterCloser
+	// Closing twice does nothing, but writing after closing fails
+	fmt.Printf("First close: %v\n", lines.Close())
+	fmt.Printf("Second close: %v\n", lines.Close())
+	_, err = lines.Write([]byte("Too late"))
+	fmt.Printf("Write after close: %v\n", err)
+	fmt.Println("")
+
+	/*
+		Readers
+
+		The io.Reader interface is the counterpart of io.Writer,
+		and the iox package provides readers to match its
+		writers.  As both sides implement the standard library
+		interfaces, io.Copy can move data from any of our readers
+		to any of our writers, without knowing their types.
+
+		Readers wrap one another just as writers do, so a
+		CountingReader can count the bytes passing through a
+		BufferedReaderCloser, or a RateLimitedReader can slow
+		any reader down.
+	*/
+	fmt.Println("#### Readers ####")
+
+	// Example 1 - Copying from our reader to our writer
+	// The reader reads 8 bytes at a time from its source, while
+	// the writer passes its data on one line at a time
+	src := iox.NewBufferedReaderCloser(strings.NewReader("Hello, readers!\nHello, io.Copy!\n"), 8)
+	counting := iox.NewCountingReader(src)
+	dst := iox.NewBufferedWriterCloser(chunkPrinter{}, iox.WithPolicy(iox.FlushLines()))
+	copied, err := io.Copy(dst, counting)
+	fmt.Printf("Copied %v bytes, counted %v, error: %v\n", copied, counting.Count(), err)
+	dst.Close()
+	src.Close()
+
+	// Example 2 - Limiting the rate of reading
+	// Reading 30 bytes at 100 bytes per second, in bursts of 10,
+	// takes roughly 200ms once the first burst is spent
+	tokens := limit.NewTokenBucket(100, 10, nil)
+	slow := iox.NewRateLimitedReader(context.Background(), strings.NewReader("Hello, rate limited reading!\n"), tokens)
+	start := time.Now()
+	copied, err = io.Copy(iox.NewConsoleWriter(os.Stdout), slow)
+	fmt.Printf("Copied %v bytes in %v, error: %v\n", copied, time.Since(start).Round(100*time.Millisecond), err)
+
+	// Example 3 - Reading line by line
+	scanner := iox.NewLineScanner(strings.NewReader("first\r\nsecond\nthird, without a newline"))
+	for scanner.Scan() {
+		fmt.Printf("%v: %q\n", scanner.Line(), scanner.Text())
+	}
+	fmt.Printf("Scan error: %v\n", scanner.Err())
+	fmt.Println("")
+
+	/*
+		Writer middleware
+
+		Because every decorator in the iox package both wraps
+		and implements WriterCloser, they can be stacked in any
+		order, each adding one behaviour to the data passing
+		through it.  Closing the outermost writer closes each
+		layer in turn, from the outside in, so that a layer like
+		the gzip writer can finish its output before the writer
+		beneath it is closed.
+	*/
+	fmt.Println("#### Writer middleware ####")
+
+	// Example 1 - Prefixing lines, and teeing them to a log
+	var log bytes.Buffer
+	prefixed := iox.NewPrefixWriter(iox.NewTeeWriter(iox.NopCloser(os.Stdout), &log), "> ")
+	fmt.Fprint(prefixed, "Hello, middleware!\nHello, ")
+	fmt.Fprint(prefixed, "again!\n")
+	prefixed.Close()
+	fmt.Printf("Logged %q\n", log.String())
+
+	// Example 2 - Counting, checksumming and compressing
+	// Closing the counting writer closes each layer beneath it
+	var compressed bytes.Buffer
+	gz := iox.NewGzipWriter(closeLogger{iox.NopCloser(&compressed), "buffer"})
+	sum := iox.NewChecksumWriter(closeLogger{gz, "gzip"})
+	count := iox.NewCountingWriter(closeLogger{sum, "checksum"})
+	message := strings.Repeat("Hello, middleware! ", 20)
+	fmt.Fprint(count, message)
+	fmt.Printf("Close error: %v\n", count.Close())
+	fmt.Printf("Wrote %v bytes, compressed to %v\n", count.Count(), compressed.Len())
+	fmt.Printf("SHA-256: %x\n", sum.Sum())
+
+	// Decompressing gives back the same bytes
+	zr, err := gzip.NewReader(&compressed)
+	if err == nil {
+		decompressed, _ := io.ReadAll(zr)
+		fmt.Printf("Round trip matches: %v\n", sha256.Sum256(decompressed) == [sha256.Size]byte(sum.Sum()))
+	}
+
+	// Example 3 - Checking the transparent decorators against the
+	// io.Writer contract
+	decorators := []struct {
+		name string
+		new  func(io.Writer) io.Writer
+	}{
+		{"MultiWriter", func(w io.Writer) io.Writer { return iox.NewMultiWriter(iox.NopCloser(w)) }},
+		{"ChecksumWriter", func(w io.Writer) io.Writer { return iox.NewChecksumWriter(iox.NopCloser(w)) }},
+		{"CountingWriter", func(w io.Writer) io.Writer { return iox.NewCountingWriter(iox.NopCloser(w)) }},
+	}
+	for _, d := range decorators {
+		if err := ioxtest.TestWriter(d.new, []byte("Hello, middleware!")); err != nil {
+			fmt.Printf("%v breaks the contract:\n%v\n", d.name, err)
+		} else {
+			fmt.Printf("%v honours the contract\n", d.name)
+		}
+	}
+	fmt.Println("")
+
+	/*
+		Extending the Incrementer
+
+		Our IntCounter increments an int with no
+		synchronisation, so two goroutines incrementing it at
+		once may both read the same value and lose one of the
+		increments.  The counter package extends Incrementer
+		with Add, Value and Reset, and implements it three ways:
+		an IntCounter with the same flaw as ours, an
+		AtomicCounter which is safe to share, and a
+		BoundedCounter which is safe to share and saturates at
+		its bounds rather than overflowing.
+
+		The extended interface includes Increment() int, so
+		every counter in the package also implements our
+		Incrementer.
+	*/
+	fmt.Println("#### Extending the Incrementer ####")
+
+	// Example 1 - Using the counters through our Incrementer
+	counters := []Incrementer{new(counter.IntCounter), new(counter.AtomicCounter), counter.NewBoundedCounter(0, 2)}
+	for _, c := range counters {
+		for i := 0; i < 3; i++ {
+			c.Increment()
+		}
+		fmt.Printf("%T incremented 3 times: %v\n", c, c.(counter.Incrementer).Value())
+	}
+
+	// Example 2 - Saturating at the bounds
+	bounded := counter.NewBoundedCounter(-5, 5)
+	fmt.Printf("Add(100) = %v, Add(-100) = %v\n", bounded.Add(100), bounded.Add(-100))
+
+	// Example 3 - Checking the counters, alone and shared
+	// between goroutines.  Only the race detector can prove that
+	// a counter is safe to share; run "go test -race ./pkg/counter"
+	impls := []struct {
+		name string
+		new  func() counter.Incrementer
+	}{
+		{"IntCounter", func() counter.Incrementer { return new(counter.IntCounter) }},
+		{"AtomicCounter", func() counter.Incrementer { return new(counter.AtomicCounter) }},
+		{"BoundedCounter", func() counter.Incrementer { return counter.NewBoundedCounter(-1000, 1000) }},
+	}
+	for _, impl := range impls {
+		err := errors.Join(
+			countertest.TestIncrementer(impl.new),
+			countertest.TestConcurrent(impl.new, 4, 250),
+		)
+		fmt.Printf("%v: %v\n", impl.name, err)
+	}
+	fmt.Println("")
+
+	/*
+		Registering implementations
+
+		A program which accepts any WriterCloser need not know
+		every implementation in advance.  Here we construct
+		writers by name from configuration strings, including
+		chunkPrinters, which the registry knows of only because
+		this lesson registered chunkFactory in its init
+		function.  The golearn tool uses the same configuration
+		strings for its -out flag.
+	*/
+	fmt.Println("#### Registering implementations ####")
+
+	// Example 1 - Listing the registered writers
+	fmt.Printf("Registered writers: %v\n", registry.Names())
+
+	// Example 2 - Constructing writers from configuration strings
+	for _, spec := range []string{"console", "buffered:size=4,policy=lines", "chunks", "chunks:size=6"} {
+		w, err := registry.New(spec)
+		if err != nil {
+			fmt.Println(err)
+			continue
+		}
+		fmt.Fprintf(w, "Hello from %v\n", spec)
+		w.Close()
+	}
+
+	// Example 3 - Rejecting bad configuration strings
+	for _, spec := range []string{"printer", "chunks:size=big", "console:colour=red"} {
+		_, err := registry.New(spec)
+		fmt.Println(err)
+	}
+	fmt.Println("")
+
+	/*
+		Substituting implementations
+
+		Any type with the right methods can stand in for an
+		interface, so code which depends on interfaces can be
+		checked without its real dependencies.  Here we check
+		greet and takeTicket with mocks, which record every
+		call made to them and return whatever we program them
+		to, including errors which are hard to cause for real.
+	*/
+	fmt.Println("#### Substituting implementations ####")
+
+	// Example 1 - Recording calls
+	mockWc := &WriterCloserMock{}
+	mockWc.WriteFunc = func(p []byte) (int, error) { return len(p), nil }
+	err = greet(mockWc, "mocks")
+	fmt.Printf("greet returned %v, wrote %q, closed %v time(s)\n",
+		err, mockWc.WriteCalls()[0].Arg0, mockWc.CloseCallCount())
+
+	// Example 2 - Programming a failure
+	// greet must still close the writer when writing fails
+	failing := &WriterCloserMock{}
+	failing.WriteReturns(0, errors.New("disk full"))
+	err = greet(failing, "mocks")
+	fmt.Printf("greet returned %q, closed %v time(s)\n", err, failing.CloseCallCount())
+
+	// Example 3 - Programming a result
+	mockInc := &IncrementerMock{}
+	mockInc.IncrementReturns(42)
+	fmt.Printf("takeTicket returned %q, incremented %v time(s)\n", takeTicket(mockInc), mockInc.IncrementCallCount())
+	fmt.Println("")
+
 	/*
 		Type switching and interfaces
 
@@ -237,8 +730,92 @@ func main() {
 		fmt.Println("I don't know what i is")
 	}
 
+	// Example of printing the dynamic type of every value in a
+	// slice of empty interfaces.  The pretty package extends the
+	// type switch above to every kind of value
+	pretty.Print([]interface{}{0, "zero", 0.0, []int{0}, nil})
+
 	// Attempting to initialize WriterCloser as value
-	//var myWc WriterCloser = BufferedWriterCloser{
-	//	buffer: bytes.NewBuffer([]byte{}),
-	//} // Will fail due to pointer receiver implementation
+	//var myWc WriterCloser = iox.BufferedWriterCloser{}
+	// Will fail due to pointer receiver implementation
+	// Run "golearn methodset ./cmd/interfaces iox.BufferedWriterCloser"
+	// to see which methods are missing from its method set
+	fmt.Println("")
+
+	/*
+		Embedding and interfaces
+
+		Embedding lets a struct reuse the fields and methods
+		of another, but it does not make one a subtype of the
+		other.  A Bird holds an Animal, but it is not an
+		Animal, and cannot be assigned to one.
+
+		Interfaces give us what embedding does not.  A Bird, a
+		Dog and a Fish are all Movers, because each has the
+		Name and Move methods, whether declared themselves or
+		promoted from the Animal they embed.  So they can be
+		used interchangeably wherever a Mover is expected.
+
+		When two embedded types share a field or method name,
+		the shallowest wins.  If they are at the same depth,
+		neither is promoted.  Selecting the name is then a
+		compile error, and a shared method drops out of the
+		method set, along with any interface which needed it.
+	*/
+	fmt.Println("#### Embedding and interfaces ####")
+
+	// Example 1 - Polymorphism through interfaces
+	// The Dog's Move is promoted from its Animal, while the
+	// Bird's and Fish's shadow it
+	sparrow := Bird{Animal{"Sparrow", 30.3}, 20.2}
+	beagle := Dog{Animal{"Beagle", 20}, "Beagle"}
+	trout := Fish{Animal{"Trout", 5}, 7}
+	for _, m := range []Mover{sparrow, beagle, trout} {
+		fmt.Printf("%T: %v\n", m, m.Move())
+	}
+
+	// Example 2 - Shadowed methods remain reachable by name
+	fmt.Println(sparrow.Move())
+	fmt.Println(sparrow.Animal.Move())
+
+	// Example 3 - Embedding is not subtyping
+	//var a Animal = sparrow // Will fail, a Bird is not an Animal
+	var a Animal = sparrow.Animal // But a Bird has an Animal
+	fmt.Println(a.Name())
+
+	// Example 4 - Embedding interfaces in structs
+	// A Pet is a Namer through the Namer it embeds, and a Leash
+	// shadows the Move of the Mover it embeds
+	pets := []Namer{Pet{beagle, "Joe"}, Pet{sparrow, "Ann"}}
+	for _, p := range pets {
+		fmt.Printf("%v belongs to %v\n", p.Name(), p.(Pet).Owner)
+	}
+	for _, m := range []Mover{beagle, Leash{beagle}} {
+		fmt.Printf("%T: %v\n", m, m.Move())
+	}
+	//Pet{}.Name() // Will panic, as the embedded Namer is nil
+
+	// Example 5 - Ambiguous methods are not promoted
+	// A Mutt gets Name from both its Dog and its Fish at the same
+	// depth, so it has no Name, and is neither a Namer nor a
+	// Mover.  The Fish's own Move is shallower than the Dog's
+	// promoted one, so the Mutt swims
+	mutt := Mutt{beagle, trout}
+	_, isNamer := interface{}(mutt).(Namer)
+	_, isMover := interface{}(mutt).(Mover)
+	_, canMove := interface{}(mutt).(interface{ Move() string })
+	fmt.Printf("Is a Mutt a Namer? %v, a Mover? %v, able to Move? %v\n", isNamer, isMover, canMove)
+	//mutt.Name() // Will fail, ambiguous selector mutt.Name
+	fmt.Println(mutt.Dog.Name(), mutt.Fish.Name())
+	fmt.Println(mutt.Move())
+
+	// Example 6 - The ambiguous selector error
+	// Type checking ambiguousSource reports the same error the
+	// compiler would
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, "labrador.go", ambiguousSource, 0)
+	if err == nil {
+		_, err = (&types.Config{}).Check("main", fset, []*ast.File{file}, nil)
+	}
+	fmt.Printf("Type checking the Labrador: %v\n", err)
 }
@@ -0,0 +1,61 @@
+package main
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestGreet(t *testing.T) {
+	errDiskFull := errors.New("disk full")
+	errClose := errors.New("already closed")
+	tests := []struct {
+		name     string
+		writeErr error
+		closeErr error
+	}{
+		{"Success", nil, nil},
+		{"WriteFails", errDiskFull, nil},
+		{"CloseFails", nil, errClose},
+		{"BothFail", errDiskFull, errClose},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := &WriterCloserMock{}
+			m.WriteFunc = func(p []byte) (int, error) {
+				if tt.writeErr != nil {
+					return 0, tt.writeErr
+				}
+				return len(p), nil
+			}
+			m.CloseReturns(tt.closeErr)
+
+			err := greet(m, "mocks")
+			for _, want := range []error{tt.writeErr, tt.closeErr} {
+				if want != nil && !errors.Is(err, want) {
+					t.Errorf("greet() = %v, want %v", err, want)
+				}
+			}
+			if err != nil && tt.writeErr == nil && tt.closeErr == nil {
+				t.Errorf("greet() = %v, want nil", err)
+			}
+			if calls := m.WriteCalls(); len(calls) != 1 || string(calls[0].Arg0) != "Hello, mocks!\n" {
+				t.Errorf("greet() wrote %q, want one write of %q", calls, "Hello, mocks!\n")
+			}
+			// The writer must be closed even when writing fails
+			if got := m.CloseCallCount(); got != 1 {
+				t.Errorf("greet() closed the writer %v time(s), want 1", got)
+			}
+		})
+	}
+}
+
+func TestTakeTicket(t *testing.T) {
+	m := &IncrementerMock{}
+	m.IncrementReturns(42)
+	if got, want := takeTicket(m), "ticket #42"; got != want {
+		t.Errorf("takeTicket() = %q, want %q", got, want)
+	}
+	if got := m.IncrementCallCount(); got != 1 {
+		t.Errorf("takeTicket() incremented %v time(s), want 1", got)
+	}
+}
@@ -0,0 +1,252 @@
+// Code generated by golearn mockgen. DO NOT EDIT.
+
+package main
+
+import (
+	"sync"
+)
+
+// WriterMock is a recording mock of Writer.
+type WriterMock struct {
+	mu sync.Mutex
+	// WriteFunc, if set, is called by Write to produce its results
+	WriteFunc func([]byte) (int, error)
+	calls     struct {
+		Write []WriterMockWriteCall
+	}
+}
+
+var _ Writer = (*WriterMock)(nil)
+
+// WriterMockWriteCall records the arguments of a call to WriterMock.Write.
+type WriterMockWriteCall struct {
+	Arg0 []byte
+}
+
+// Write records the call, then returns the results of WriteFunc, if set.
+func (m *WriterMock) Write(arg0 []byte) (r0 int, r1 error) {
+	m.mu.Lock()
+	m.calls.Write = append(m.calls.Write, WriterMockWriteCall{Arg0: append([]byte(nil), arg0...)})
+	fn := m.WriteFunc
+	m.mu.Unlock()
+	if fn != nil {
+		return fn(arg0)
+	}
+	return
+}
+
+// WriteReturns programs Write to return the given results.
+func (m *WriterMock) WriteReturns(r0 int, r1 error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.WriteFunc = func([]byte) (int, error) {
+		return r0, r1
+	}
+}
+
+// WriteCalls returns the recorded calls to Write, oldest first.
+func (m *WriterMock) WriteCalls() []WriterMockWriteCall {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]WriterMockWriteCall(nil), m.calls.Write...)
+}
+
+// WriteCallCount returns the number of recorded calls to Write.
+func (m *WriterMock) WriteCallCount() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return len(m.calls.Write)
+}
+
+// CloserMock is a recording mock of Closer.
+type CloserMock struct {
+	mu sync.Mutex
+	// CloseFunc, if set, is called by Close to produce its results
+	CloseFunc func() error
+	calls     struct {
+		Close []CloserMockCloseCall
+	}
+}
+
+var _ Closer = (*CloserMock)(nil)
+
+// CloserMockCloseCall records the arguments of a call to CloserMock.Close.
+type CloserMockCloseCall struct{}
+
+// Close records the call, then returns the results of CloseFunc, if set.
+func (m *CloserMock) Close() (r0 error) {
+	m.mu.Lock()
+	m.calls.Close = append(m.calls.Close, CloserMockCloseCall{})
+	fn := m.CloseFunc
+	m.mu.Unlock()
+	if fn != nil {
+		return fn()
+	}
+	return
+}
+
+// CloseReturns programs Close to return the given results.
+func (m *CloserMock) CloseReturns(r0 error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.CloseFunc = func() error {
+		return r0
+	}
+}
+
+// CloseCalls returns the recorded calls to Close, oldest first.
+func (m *CloserMock) CloseCalls() []CloserMockCloseCall {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]CloserMockCloseCall(nil), m.calls.Close...)
+}
+
+// CloseCallCount returns the number of recorded calls to Close.
+func (m *CloserMock) CloseCallCount() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return len(m.calls.Close)
+}
+
+// WriterCloserMock is a recording mock of WriterCloser.
+type WriterCloserMock struct {
+	mu sync.Mutex
+	// CloseFunc, if set, is called by Close to produce its results
+	CloseFunc func() error
+	// WriteFunc, if set, is called by Write to produce its results
+	WriteFunc func([]byte) (int, error)
+	calls     struct {
+		Close []WriterCloserMockCloseCall
+		Write []WriterCloserMockWriteCall
+	}
+}
+
+var _ WriterCloser = (*WriterCloserMock)(nil)
+
+// WriterCloserMockCloseCall records the arguments of a call to WriterCloserMock.Close.
+type WriterCloserMockCloseCall struct{}
+
+// Close records the call, then returns the results of CloseFunc, if set.
+func (m *WriterCloserMock) Close() (r0 error) {
+	m.mu.Lock()
+	m.calls.Close = append(m.calls.Close, WriterCloserMockCloseCall{})
+	fn := m.CloseFunc
+	m.mu.Unlock()
+	if fn != nil {
+		return fn()
+	}
+	return
+}
+
+// CloseReturns programs Close to return the given results.
+func (m *WriterCloserMock) CloseReturns(r0 error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.CloseFunc = func() error {
+		return r0
+	}
+}
+
+// CloseCalls returns the recorded calls to Close, oldest first.
+func (m *WriterCloserMock) CloseCalls() []WriterCloserMockCloseCall {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]WriterCloserMockCloseCall(nil), m.calls.Close...)
+}
+
+// CloseCallCount returns the number of recorded calls to Close.
+func (m *WriterCloserMock) CloseCallCount() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return len(m.calls.Close)
+}
+
+// WriterCloserMockWriteCall records the arguments of a call to WriterCloserMock.Write.
+type WriterCloserMockWriteCall struct {
+	Arg0 []byte
+}
+
+// Write records the call, then returns the results of WriteFunc, if set.
+func (m *WriterCloserMock) Write(arg0 []byte) (r0 int, r1 error) {
+	m.mu.Lock()
+	m.calls.Write = append(m.calls.Write, WriterCloserMockWriteCall{Arg0: append([]byte(nil), arg0...)})
+	fn := m.WriteFunc
+	m.mu.Unlock()
+	if fn != nil {
+		return fn(arg0)
+	}
+	return
+}
+
+// WriteReturns programs Write to return the given results.
+func (m *WriterCloserMock) WriteReturns(r0 int, r1 error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.WriteFunc = func([]byte) (int, error) {
+		return r0, r1
+	}
+}
+
+// WriteCalls returns the recorded calls to Write, oldest first.
+func (m *WriterCloserMock) WriteCalls() []WriterCloserMockWriteCall {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]WriterCloserMockWriteCall(nil), m.calls.Write...)
+}
+
+// WriteCallCount returns the number of recorded calls to Write.
+func (m *WriterCloserMock) WriteCallCount() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return len(m.calls.Write)
+}
+
+// IncrementerMock is a recording mock of Incrementer.
+type IncrementerMock struct {
+	mu sync.Mutex
+	// IncrementFunc, if set, is called by Increment to produce its results
+	IncrementFunc func() int
+	calls         struct {
+		Increment []IncrementerMockIncrementCall
+	}
+}
+
+var _ Incrementer = (*IncrementerMock)(nil)
+
+// IncrementerMockIncrementCall records the arguments of a call to IncrementerMock.Increment.
+type IncrementerMockIncrementCall struct{}
+
+// Increment records the call, then returns the results of IncrementFunc, if set.
+func (m *IncrementerMock) Increment() (r0 int) {
+	m.mu.Lock()
+	m.calls.Increment = append(m.calls.Increment, IncrementerMockIncrementCall{})
+	fn := m.IncrementFunc
+	m.mu.Unlock()
+	if fn != nil {
+		return fn()
+	}
+	return
+}
+
+// IncrementReturns programs Increment to return the given results.
+func (m *IncrementerMock) IncrementReturns(r0 int) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.IncrementFunc = func() int {
+		return r0
+	}
+}
+
+// IncrementCalls returns the recorded calls to Increment, oldest first.
+func (m *IncrementerMock) IncrementCalls() []IncrementerMockIncrementCall {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]IncrementerMockIncrementCall(nil), m.calls.Increment...)
+}
+
+// IncrementCallCount returns the number of recorded calls to Increment.
+func (m *IncrementerMock) IncrementCallCount() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return len(m.calls.Increment)
+}
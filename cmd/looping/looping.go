@@ -3,6 +3,8 @@ package main
 import (
 	"fmt"
 	"strconv"
+
+	"github.com/whatsacomputertho/go-learn/pkg/dataset"
 )
 
 func main() {
@@ -176,20 +178,24 @@ MyLoop:
 		fmt.Println("Index: " + strconv.Itoa(k) + "; Value: " + strconv.Itoa(v))
 	}
 
-	// Loop through a map using for-range loop
-	statePopulations := map[string]int{
-		"California":   39250017,
-		"Texas":        27862596,
-		"Florida":      20612439,
-		"New York":     19745289,
-		"Pennsylvania": 12802503,
-		"Illinois":     12801539,
-		"Ohio":         11614373,
+	// Loop through a map using for-range loop - the map is
+	// loaded from the states.csv dataset shipped with the
+	// dataset package
+	statePopulations, err := dataset.LoadFS(dataset.Builtin, "states.csv")
+	if err != nil {
+		panic(err)
 	}
 	for k, v := range statePopulations {
 		fmt.Println("Key: " + k + "; Value: " + strconv.Itoa(v))
 	}
 
+	// Maps are looped through in an unspecified order, so to
+	// loop through one predictably we loop through its sorted
+	// keys instead
+	for _, k := range statePopulations.Keys() {
+		fmt.Println("Key: " + k + "; Value: " + strconv.Itoa(statePopulations[k]))
+	}
+
 	// Loop through a string using for-range loop
 	myStr := "Hello, go!"
 	for k, v := range myStr {
@@ -1,8 +1,26 @@
 package main
 
 import (
+	"encoding/json"
+	"errors"
 	"fmt"
+	"os"
+	"os/exec"
 	"reflect"
+	"runtime"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/whatsacomputertho/go-learn/pkg/clone"
+	"github.com/whatsacomputertho/go-learn/pkg/cmap"
+	"github.com/whatsacomputertho/go-learn/pkg/dataset"
+	"github.com/whatsacomputertho/go-learn/pkg/defaults"
+	"github.com/whatsacomputertho/go-learn/pkg/diff"
+	"github.com/whatsacomputertho/go-learn/pkg/mapx"
+	"github.com/whatsacomputertho/go-learn/pkg/pretty"
+	"github.com/whatsacomputertho/go-learn/pkg/roundtrip"
+	"github.com/whatsacomputertho/go-learn/pkg/validate"
 )
 
 /*
@@ -28,7 +46,7 @@ Note that this Person struct is instantiated and
 manipulated below.
 */
 type Person struct {
-	Name       string   // Public
+	Name       string   `json:"name" xml:"name"` // Public
 	birthDay   int      // Private
 	birthMonth int      // Private
 	interests  []string // Private
@@ -54,7 +72,9 @@ it does not give us a way to define a bird as a subtype
 of the larger animal type.
 
 In order to do this, we will later explore interfaces
-in go.
+in go.  The interfaces lesson's "Embedding and interfaces"
+section extends this Animal and Bird with a Dog and a Fish,
+and uses interfaces to use them interchangeably.
 
 Embedding in go makes the most sense when we simply want
 behavior of a complex base type to be carried forward
@@ -63,13 +83,13 @@ It does not make sense when we want a sub-type to be
 used interchangably with instances of its parent types.
 */
 type Animal struct {
-	Name     string
-	SpeedMPH float32
+	Name     string  `default:"Unknown animal" json:"name" xml:"name"`
+	SpeedMPH float32 `default:"1" json:"speedMph,omitempty" xml:"speedMph,omitempty"`
 }
 
 type Bird struct {
-	Animal     // Embedding - a bird has animal properties
-	WingspanCM float32
+	Animal             // Embedding - a bird has animal properties
+	WingspanCM float32 `json:"wingspanCm" xml:"wingspanCm,attr"`
 }
 
 /*
@@ -91,14 +111,76 @@ tags, which we explore below.
 Generally, tags are used in validation frameworks to
 ensure that fields adhere to certain arbitrary constraints
 such as those suggested below, like required:"true" or
-required:"false".
+required:"false".  The standard library's encoding packages
+read tags too: json:"isGood" names the field in JSON, and
+xml:"good,attr" encodes it as an XML attribute named good.
 */
 type Dog struct {
-	IsGood bool   `required:"false" default:"true" mustSetEqualTo:"true"`
-	Breed  string `required:"true" max:"100"`
+	IsGood bool   `required:"false" default:"true" mustSetEqualTo:"true" json:"isGood" xml:"good,attr"`
+	Breed  string `required:"true" max:"100" json:"breed,omitempty" xml:"breed,omitempty"`
+}
+
+/*
+Kennel nests our Dog struct in a slice, so that we can see the
+validate package check every dog in the kennel, and report
+which of them is invalid.
+*/
+type Kennel struct {
+	Name string `required:"true"`
+	Dogs []Dog  `min:"1" max:"3"`
+}
+
+/*
+Walk gives the defaults package more kinds of field to parse,
+alongside the Bird whose embedded Animal has defaults of its own.
+*/
+type Walk struct {
+	Dog      Dog
+	Length   time.Duration `default:"45m"`
+	Stops    []string      `default:"park, pond, home"`
+	Distance float64       `default:"2.5"`
+	Laps     int           `default:"many"`
+}
+
+/*
+Custom encoding
+
+The encoding/json package can only see a struct's exported
+fields, so our Person's birthday and interests are normally
+left out of its JSON.  A type can take control of its own
+encoding by implementing the json.Marshaler and
+json.Unmarshaler interfaces.  Here our Person copies its
+fields, exported or not, to and from personJSON, whose fields
+are all exported.
+*/
+type personJSON struct {
+	Name       string   `json:"name"`
+	BirthDay   int      `json:"birthDay,omitempty"`
+	BirthMonth int      `json:"birthMonth,omitempty"`
+	Interests  []string `json:"interests,omitempty"`
+}
+
+func (p Person) MarshalJSON() ([]byte, error) {
+	return json.Marshal(personJSON{p.Name, p.birthDay, p.birthMonth, p.interests})
+}
+
+func (p *Person) UnmarshalJSON(data []byte) error {
+	var pj personJSON
+	if err := json.Unmarshal(data, &pj); err != nil {
+		return err
+	}
+	*p = Person{pj.Name, pj.BirthDay, pj.BirthMonth, pj.Interests}
+	return nil
 }
 
 func main() {
+	// Run as a child process by the concurrent map access
+	// section, to crash without taking the lesson down with it
+	if len(os.Args) > 1 && os.Args[1] == crashArg {
+		writeConcurrently()
+		return
+	}
+
 	/*
 		Creation of maps
 
@@ -116,7 +198,8 @@ func main() {
 	fmt.Println("#### Creation of maps ####")
 
 	// An example of a map containing string: int
-	// key-value pairs
+	// key-value pairs - the same populations ship with the
+	// dataset package, and are loaded from a file below
 	statePopulations := map[string]int{
 		"California":   39250017,
 		"Texas":        27862596,
@@ -232,6 +315,179 @@ func main() {
 	fmt.Printf("newMap: (%T) %v\n", newMap, newMap)
 	fmt.Println("")
 
+	/*
+		Querying map data
+
+		Rather than writing a map literal by hand, we can load
+		the same state populations from a file.  The dataset
+		package reads them from CSV or JSON into a Dataset,
+		which is declared as a map[string]int and so can be
+		indexed, ranged over and deleted from like any map.
+
+		Its methods add the operations maps lack.  Since a map
+		is ranged over in an unspecified order, they first sort
+		its entries, by key or by value, and build the rest on
+		top of that: the largest values, filtering by a
+		predicate function, and aggregating every value into
+		one.
+	*/
+	fmt.Println("#### Querying map data ####")
+
+	// Example 1 - Loading a map from a JSON file
+	states, err := dataset.LoadFS(dataset.Builtin, "states.json")
+	if err != nil {
+		panic(err)
+	}
+	fmt.Printf("states: (%T) %v\n", states, states)
+	fmt.Printf("states[\"Ohio\"]: %v\n", states["Ohio"])
+
+	// Example 2 - Looping through a map in sorted order
+	for _, e := range states.Entries() {
+		fmt.Printf("%v: %v\n", e.Key, e.Value)
+	}
+
+	// Example 3 - Finding the largest values
+	fmt.Printf("states.Top(3): %v\n", states.Top(3))
+
+	// Example 4 - Filtering by a predicate function
+	large := states.Filter(func(e dataset.Entry) bool {
+		return e.Value > 15000000
+	})
+	fmt.Printf("large: %v\n", large.Keys())
+
+	// Example 5 - Aggregating every value into one
+	total := states.Aggregate(0, func(sum int, e dataset.Entry) int {
+		return sum + e.Value
+	})
+	fmt.Printf("total: %v\n", total)
+	fmt.Printf("states.Summarize(): %+v\n", states.Summarize())
+	fmt.Println("")
+
+	/*
+		Ordered maps
+
+		Since maps are not guaranteed a particular key order,
+		printing or ranging over the same map can give a
+		different order from run to run.  When order matters,
+		we can either sort the keys each time, as we did
+		above, or keep them in order as they are set.
+
+		The mapx package's generic OrderedMap keeps its keys
+		in the order they were first set, by pairing a map
+		with a linked list.  Its SortedMap keeps them in sorted
+		order, in a balanced binary search tree.  Neither can
+		use the square bracket syntax or the range keyword of
+		builtin maps, so they are handled with methods instead.
+	*/
+	fmt.Println("#### Ordered maps ####")
+
+	// Example 1 - An OrderedMap ranges in insertion order
+	visits := mapx.NewOrderedMap[string, int]()
+	visits.Set("Ohio", 2)
+	visits.Set("Texas", 1)
+	visits.Set("California", 3)
+	visits.Set("Ohio", 4) // Updating a key does not move it
+	visits.Range(func(state string, count int) bool {
+		fmt.Printf("%v: %v\n", state, count)
+		return true
+	})
+
+	// Example 2 - A SortedMap ranges in key order, whatever
+	// order its keys were set in
+	sortedStates := mapx.NewSortedMap[string, int]()
+	for state, population := range states {
+		sortedStates.Set(state, population)
+	}
+	fmt.Printf("mapx.Keys(sortedStates): %v\n", mapx.Keys[string, int](sortedStates))
+
+	// Example 3 - Reading and deleting use methods rather than
+	// square brackets and the delete function
+	ohio, ok := sortedStates.Get("Ohio")
+	fmt.Printf("sortedStates.Get(\"Ohio\"): %v %v\n", ohio, ok)
+	fmt.Printf("sortedStates.Delete(\"Ohio\"): %v\n", sortedStates.Delete("Ohio"))
+	first, _, _ := sortedStates.Min()
+	fmt.Printf("sortedStates.Len(): %v, first key: %v\n", sortedStates.Len(), first)
+	fmt.Println("")
+
+	/*
+		Concurrent map access
+
+		Since maps are reference types, every goroutine handed
+		a map shares the very same map.  But builtin maps are
+		not safe for concurrent use.  Reading a map from many
+		goroutines at once is fine, but if one goroutine
+		writes to a map while another reads or writes it, the
+		map's internal state can be corrupted.  The runtime
+		checks for this, and when it catches it, it crashes
+		the whole program with "fatal error: concurrent map
+		writes".  Unlike a panic, a fatal error cannot be
+		recovered from, so we run the crashing code in a child
+		process and print how it ended.
+
+		To share a map between goroutines, its access must be
+		guarded.  The cmap package's Map guards a builtin map
+		with a sync.RWMutex, as the goroutines lesson guards
+		its counter.  Its Sharded map splits the keys between
+		many such maps, so that goroutines using different
+		keys need not wait for each other.  The standard
+		library's sync.Map is another option, tuned for keys
+		which are written once and read many times.
+
+		A guarded Get followed by a guarded Set is still not
+		atomic, as another goroutine can write between them.
+		Update reads and writes a key under a single lock.
+	*/
+	fmt.Println("#### Concurrent map access ####")
+
+	// Example 1 - Writing to a builtin map from many goroutines
+	// crashes the program, here a child process
+	crash, err := runConcurrentWrites()
+	if err != nil {
+		fmt.Printf("could not run the child process: %v\n", err)
+	} else {
+		fmt.Printf("child process: %v\n", crash)
+	}
+
+	// Example 2 - A cmap.Map can be shared, and Update counts
+	// each visit without losing any
+	var wg sync.WaitGroup
+	visitCounts := cmap.NewMap[string, int]()
+	for i := 0; i < 100; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			state := []string{"Ohio", "Texas", "California"}[i%3]
+			visitCounts.Update(state, func(n int, _ bool) int { return n + 1 })
+		}(i)
+	}
+	wg.Wait()
+	fmt.Printf("visitCounts.Len(): %v\n", visitCounts.Len())
+	for _, state := range []string{"California", "Ohio", "Texas"} {
+		n, _ := visitCounts.Get(state)
+		fmt.Printf("visitCounts.Get(%q): %v\n", state, n)
+	}
+
+	// Example 3 - A Sharded map spreads its keys between shards,
+	// each with its own lock
+	shardedStates := cmap.NewSharded[string, int](8, cmap.HashString)
+	for state, population := range states {
+		wg.Add(1)
+		go func(state string, population int) {
+			defer wg.Done()
+			shardedStates.Set(state, population)
+		}(state, population)
+	}
+	wg.Wait()
+	fmt.Printf("shardedStates.Shards(): %v, Len(): %v\n", shardedStates.Shards(), shardedStates.Len())
+
+	// Example 4 - The same goes for sync.Map, which stores its
+	// keys and values as any
+	var syncStates sync.Map
+	syncStates.Store("Ohio", 11614373)
+	ohioAny, _ := syncStates.Load("Ohio")
+	fmt.Printf("syncStates.Load(\"Ohio\"): (%T) %v\n", ohioAny, ohioAny)
+	fmt.Println("")
+
 	/*
 		Creation of structs (cont.)
 
@@ -314,7 +570,7 @@ func main() {
 	fmt.Printf("myPerson.interests[0]: (%T) %v\n", myPerson.interests[0], myPerson.interests[0])
 
 	// Example of structs as value types
-	yourPerson := myPerson // Copy happens here
+	yourPerson := myPerson // Copy happens here, but a shallow one
 	yourPerson.Name = "Hank"
 	fmt.Printf("myPerson.Name: %v\n", myPerson.Name)
 	fmt.Printf("yourPerson.Name: %v\n", yourPerson.Name)
@@ -326,6 +582,55 @@ func main() {
 	fmt.Printf("theirPerson.Name: %v\n", theirPerson.Name)
 	fmt.Println("")
 
+	/*
+		Copying structs deeply
+
+		Assigning a struct copies each of its fields, but only
+		the fields themselves.  Our Person's interests field is
+		a slice, and a slice is a small header pointing at an
+		underlying array, so the copy's interests point at the
+		very same array as the original's.  Changing an element
+		through either is seen through both, even though the
+		structs themselves are separate.  The same is true of
+		map and pointer fields.
+
+		This is called a shallow copy.  To make a deep copy, we
+		must copy what the slices, maps and pointers refer to
+		as well.  We can do so by hand for a struct we know,
+		or use the clone package's DeepCopy, which uses the
+		reflect package to do so for any value, including the
+		unexported fields of our Person.
+	*/
+	fmt.Println("#### Copying structs deeply ####")
+
+	// Example 1 - A shallow copy shares its slice's elements
+	shallowPerson := myPerson
+	shallowPerson.interests[0] = "Chess"
+	fmt.Printf("myPerson.interests: %v\n", myPerson.interests)
+	fmt.Printf("shallowPerson.interests: %v\n", shallowPerson.interests)
+
+	// Example 2 - But appending to a full slice copies it into
+	// a new array, after which the two no longer share
+	shallowPerson.interests = append(shallowPerson.interests, "Darts")
+	shallowPerson.interests[0] = "Bowling"
+	fmt.Printf("myPerson.interests: %v\n", myPerson.interests)
+	fmt.Printf("shallowPerson.interests: %v\n", shallowPerson.interests)
+
+	// Example 3 - Copying the slice by hand
+	handPerson := myPerson
+	handPerson.interests = make([]string, len(myPerson.interests))
+	copy(handPerson.interests, myPerson.interests)
+	handPerson.interests[0] = "Golf"
+	fmt.Printf("myPerson.interests: %v\n", myPerson.interests)
+	fmt.Printf("handPerson.interests: %v\n", handPerson.interests)
+
+	// Example 4 - Copying any value deeply with DeepCopy
+	deepPerson := clone.DeepCopy(myPerson)
+	deepPerson.interests[0] = "Tennis"
+	fmt.Printf("myPerson.interests: %v\n", myPerson.interests)
+	fmt.Printf("deepPerson.interests: %v\n", deepPerson.interests)
+	fmt.Println("")
+
 	/*
 		Embedding demonstration
 
@@ -370,6 +675,46 @@ func main() {
 	fmt.Printf("expBird.Animal: (%T) %v\n", expBird.Animal, expBird.Animal)
 	fmt.Println("")
 
+	/*
+		Comparing structs
+
+		Structs can be compared with == only when every one of
+		their fields can be.  Our Bird's fields are all numbers
+		and strings, so two birds can be compared with ==, but
+		our Person's interests field is a slice, and slices
+		cannot be compared with ==, so neither can people.
+
+		Either way, == only tells us whether two structs are
+		equal, not how they differ.  The diff package compares
+		two values field by field, walking into embedded
+		structs and slice elements, and reports the path of
+		every field which differs.  Here we use it to see
+		exactly what the copy and pointer examples above
+		changed.
+	*/
+	fmt.Println("#### Comparing structs ####")
+
+	// Example 1 - Comparing structs with ==
+	fmt.Printf("myBird == expBird: %v\n", myBird == expBird)
+	// fmt.Println(myPerson == yourPerson) // Does not compile
+
+	// Example 2 - Comparing birds field by field, including
+	// the fields of their embedded Animal
+	fmt.Printf("diff.Diff(myBird, expBird):\n%v", diff.Diff(myBird, expBird))
+
+	// Example 3 - The value copy changed only its Name, since
+	// its interests still share their elements with myPerson
+	fmt.Printf("diff.Diff(myPerson, yourPerson):\n%v", diff.Diff(myPerson, yourPerson))
+
+	// Example 4 - The pointer changed myPerson itself
+	fmt.Printf("diff.Diff(myPerson, *theirPerson):\n%v", diff.Diff(myPerson, *theirPerson))
+
+	// Example 5 - The slice elements which the shallow and
+	// deep copies changed
+	fmt.Printf("diff.Diff(myPerson, shallowPerson):\n%v", diff.Diff(myPerson, shallowPerson))
+	fmt.Printf("diff.Diff(myPerson, deepPerson):\n%v", diff.Diff(myPerson, deepPerson))
+	fmt.Println("")
+
 	/*
 		Struct tagging demonstration
 
@@ -383,4 +728,206 @@ func main() {
 	t := reflect.TypeOf(Dog{})
 	field, _ := t.FieldByName("Breed")
 	fmt.Println(field.Tag)
+
+	// The validate package interprets these tags for us
+	// Example 1 - Applying defaults, then checking constraints
+	// IsGood is given its default, but Breed is missing
+	unknownDog := Dog{}
+	fmt.Printf("validate.Struct(&unknownDog): %v\n", validate.Struct(&unknownDog))
+	fmt.Printf("unknownDog: %+v\n", unknownDog)
+
+	// Example 2 - Checking nested structs
+	// Every failure is reported, with the path of its field
+	kennel := Kennel{
+		Dogs: []Dog{
+			{Breed: "Beagle"},
+			{Breed: strings.Repeat("Doodle", 20)},
+			{},
+		},
+	}
+	fmt.Printf("validate.Struct(&kennel):\n%v\n", validate.Struct(&kennel))
+	fmt.Println("")
+
+	/*
+		Default values
+
+		Filling in defaults is a separate job from validation,
+		so the defaults package does it alone, and the validate
+		package calls it before checking any constraints.  Each
+		default tag is parsed according to its field's type, and
+		embedded structs like the Animal in our Bird are given
+		their defaults too.  A default which cannot be parsed
+		is reported as an error, and its field is left alone.
+	*/
+	fmt.Println("#### Default values ####")
+
+	// Example 1 - A zero Dog becomes a good dog
+	defaultDog := Dog{}
+	fmt.Printf("defaults.Apply(&defaultDog): %v\n", defaults.Apply(&defaultDog))
+	fmt.Printf("defaultDog: %+v\n", defaultDog)
+
+	// Example 2 - Embedded structs are given their defaults,
+	// but fields which are already set are kept
+	defaultBird := Bird{WingspanCM: 20.2}
+	defaults.Apply(&defaultBird)
+	fmt.Printf("defaultBird: %+v\n", defaultBird)
+
+	// Example 3 - Durations, slices and floats are parsed,
+	// and a default which is not an int is reported
+	walk := Walk{}
+	fmt.Printf("defaults.Apply(&walk): %v\n", defaults.Apply(&walk))
+	fmt.Printf("walk: %+v\n", walk)
+	fmt.Println("")
+
+	/*
+		Encoding structs
+
+		The encoding/json and encoding/xml packages use the
+		reflect package to encode any struct, reading each
+		field's json or xml tag for the name to encode it
+		under.  A field without a tag is encoded under its own
+		name, and a tag of "-" leaves the field out.  Adding
+		omitempty leaves the field out whenever it holds its
+		zero value.
+
+		Only exported fields can be seen by these packages,
+		so unexported fields are silently lost.  The fields
+		of embedded structs, like the Animal in our Bird, are
+		promoted into the outer struct's JSON or XML, just as
+		they are promoted in go.
+
+		The roundtrip package encodes a value, decodes it
+		again, and uses the diff package to report what was
+		lost in between.
+	*/
+	fmt.Println("#### Encoding structs ####")
+
+	// Example 1 - Embedded fields are flattened, and a zero
+	// SpeedMPH is left out by omitempty
+	penguin := Bird{Animal: Animal{Name: "Emperor Penguin"}, WingspanCM: 76}
+	birdJSON, err := roundtrip.JSON(penguin)
+	printRoundTrip("JSON", birdJSON, err)
+	birdXML, err := roundtrip.XML(penguin)
+	printRoundTrip("XML", birdXML, err)
+
+	// Example 2 - Dogs survive the round trip intact, as all of
+	// their fields are exported
+	beagle := Dog{IsGood: true, Breed: "Beagle"}
+	dogJSON, err := roundtrip.JSON(beagle)
+	printRoundTrip("JSON", dogJSON, err)
+	dogXML, err := roundtrip.XML(beagle)
+	printRoundTrip("XML", dogXML, err)
+
+	// Example 3 - Without its MarshalJSON method, a Person's
+	// unexported fields are lost.  A type defined from Person
+	// has the same fields, but none of its methods
+	type plainPerson Person
+	plainJSON, err := roundtrip.JSON(plainPerson(myPerson))
+	printRoundTrip("JSON", plainJSON, err)
+
+	// Example 4 - With its MarshalJSON and UnmarshalJSON
+	// methods, a Person survives intact, but XML still loses
+	// its unexported fields
+	customJSON, err := roundtrip.JSON(myPerson)
+	printRoundTrip("JSON", customJSON, err)
+	personXML, err := roundtrip.XML(myPerson)
+	printRoundTrip("XML", personXML, err)
+	fmt.Println("")
+
+	/*
+		Printing any value
+
+		The %v verb prints a struct's field values, but not its
+		field names or types, and not the values behind its
+		pointers.  The pretty package walks any value with type
+		switches and the reflect package, and prints it as an
+		indented tree with every part's type, including the
+		unexported fields of our Person and the Animal embedded
+		in our Bird.
+	*/
+	fmt.Println("#### Printing any value ####")
+
+	// Example 1 - Printing structs, including embedded structs
+	pretty.Print(myPerson)
+	pretty.Print(expBird)
+
+	// Example 2 - Printing maps, slices and pointers
+	flock := map[string][]*Bird{
+		"garden": {&myBird, &expBird},
+		"empty":  nil,
+	}
+	pretty.Print(flock)
+
+	// Example 3 - Printing a value which contains itself
+	// Each pointer is labelled when first printed, and a pointer
+	// back to a value already being printed is shown as a cycle
+	type node struct {
+		Value int
+		Next  *node
+	}
+	ring := &node{Value: 1}
+	ring.Next = &node{Value: 2, Next: ring}
+	pretty.Print(ring)
+}
+
+/*
+Concurrent map access
+
+Used in the concurrent map access example.  runConcurrentWrites
+runs this program again as a child process, passing it crashArg
+so that it calls writeConcurrently instead of the lesson.  Many
+goroutines then write to one builtin map until the runtime
+catches them, and runConcurrentWrites reports the fatal error
+the child printed.
+*/
+const crashArg = "concurrent-map-writes"
+
+func writeConcurrently() {
+	// Give each goroutine a thread of its own, so that they
+	// interleave even on a single CPU
+	runtime.GOMAXPROCS(8)
+	m := map[int]int{}
+	deadline := time.Now().Add(5 * time.Second)
+	var wg sync.WaitGroup
+	for g := 0; g < 8; g++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; time.Now().Before(deadline); i++ {
+				m[i%100] = i
+			}
+		}()
+	}
+	wg.Wait()
+	fmt.Println("no concurrent writes were caught this time")
+}
+
+func runConcurrentWrites() (string, error) {
+	exe, err := os.Executable()
+	if err != nil {
+		return "", err
+	}
+	out, err := exec.Command(exe, crashArg).CombinedOutput()
+	var exitErr *exec.ExitError
+	if err != nil && !errors.As(err, &exitErr) {
+		return "", err
+	}
+	// The fatal error is followed by every goroutine's stack, so
+	// keep only its first line
+	first, _, _ := strings.Cut(strings.TrimSpace(string(out)), "\n")
+	if err != nil {
+		first += " (" + err.Error() + ")"
+	}
+	return first, nil
+}
+
+// printRoundTrip prints the encoding made by a round trip and what
+// was lost in it, or the error which stopped it.
+func printRoundTrip[T any](format string, r *roundtrip.Result[T], err error) {
+	if err != nil {
+		fmt.Printf("%v: %v\n", format, err)
+		return
+	}
+	fmt.Printf("%v: %s\n", format, r.Encoded)
+	fmt.Printf("Lost:\n%v", r.Lost)
 }
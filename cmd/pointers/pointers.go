@@ -2,6 +2,11 @@ package main
 
 import (
 	"fmt"
+	"os"
+	"reflect"
+	"unsafe"
+
+	"github.com/whatsacomputertho/go-learn/pkg/layout"
 )
 
 // Used in pointer creation examples
@@ -9,6 +14,20 @@ type myStruct struct {
 	foo int
 }
 
+// Used in memory layout examples, paddedStruct and packedStruct
+// hold the same fields in a different order
+type paddedStruct struct {
+	isSet bool
+	count int64
+	isOn  bool
+}
+
+type packedStruct struct {
+	count int64
+	isSet bool
+	isOn  bool
+}
+
 func main() {
 	/*
 		Intro to pointers & value types
@@ -178,4 +197,55 @@ func main() {
 	fmt.Println(myRefMap, otherMap)    // map[foo:bar fizz:buzz] map[foo:bar fizz:buzz]
 	myRefMap["foo"] = "qux"            // Mutate original map
 	fmt.Println(myRefSlc, otherRefSlc) // map[foo:qux fizz:buzz] map[foo:qux fizz:buzz]
+	fmt.Println("")
+
+	/*
+		Memory layout
+
+		A pointer holds the address of a value in memory, and
+		the unsafe package lets us see how values are laid out
+		at those addresses.  unsafe.Sizeof reports how many
+		bytes a value occupies, unsafe.Alignof reports the
+		number its address must be a multiple of, and
+		unsafe.Offsetof reports how far into a struct one of
+		its fields begins.
+
+		A struct's fields are laid out in the order they are
+		declared, and padding is inserted wherever a field
+		would otherwise begin at an address which is not a
+		multiple of its alignment.  Declaring the same fields
+		in a different order can therefore change the size of
+		a struct.  Run "golearn layout" to see the layout of
+		any struct, and an order for its fields which needs
+		less padding.
+
+		The unsafe package also makes the pointer arithmetic
+		we saw was unsupported possible, though as its name
+		suggests, the compiler can no longer keep us safe when
+		we use it.
+	*/
+	fmt.Println("#### Memory layout ####")
+
+	// Example of the size and alignment of a struct
+	fmt.Println(unsafe.Sizeof(myStruct{}), unsafe.Alignof(myStruct{})) // 8 8
+
+	// Example of padding between a struct's fields
+	var ps paddedStruct
+	fmt.Println(unsafe.Offsetof(ps.isSet), unsafe.Offsetof(ps.count), unsafe.Offsetof(ps.isOn)) // 0 8 16
+	fmt.Println(unsafe.Sizeof(ps))                                                              // 24
+
+	// Example of the same fields in an order which needs less
+	// padding
+	var pk packedStruct
+	fmt.Println(unsafe.Offsetof(pk.count), unsafe.Offsetof(pk.isSet), unsafe.Offsetof(pk.isOn)) // 0 8 9
+	fmt.Println(unsafe.Sizeof(pk))                                                              // 16
+
+	// Example of printing a layout with the layout package,
+	// which reads the same numbers with the reflect package
+	padded, _ := layout.FromReflect(reflect.TypeOf(ps))
+	padded.Fprint(os.Stdout)
+
+	// Example of pointer arithmetic with the unsafe package
+	arrPtrB := (*int)(unsafe.Add(unsafe.Pointer(arrPtrA), unsafe.Sizeof(myArr[0])))
+	fmt.Println(*arrPtrB) // 2
 }
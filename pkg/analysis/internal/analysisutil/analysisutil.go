@@ -0,0 +1,168 @@
+/*
+Package analysisutil holds helpers shared by the analyzers in
+this repository which reason about goroutines.
+*/
+package analysisutil
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+	"go/types"
+	"path/filepath"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/types/typeutil"
+)
+
+// LessonURL returns the URL of a section of one of the lessons in
+// this repository.
+func LessonURL(lesson, anchor string) string {
+	return "https://github.com/whatsacomputertho/go-learn/tree/main/cmd/" + lesson + "#" + anchor
+}
+
+// CalleeName returns the full name of the function or method
+// statically called by call, such as "time.Sleep" or
+// "(*sync.WaitGroup).Add", or the empty string for dynamic calls.
+func CalleeName(info *types.Info, call *ast.CallExpr) string {
+	fn, ok := typeutil.Callee(info, call).(*types.Func)
+	if !ok {
+		return ""
+	}
+	return fn.FullName()
+}
+
+// Receiver returns the receiver expression of a method call, such
+// as "wg" in wg.Add(1), or nil if call is not a method call.
+func Receiver(call *ast.CallExpr) ast.Expr {
+	sel, ok := ast.Unparen(call.Fun).(*ast.SelectorExpr)
+	if !ok {
+		return nil
+	}
+	return sel.X
+}
+
+// InspectGoroutine calls f for each node in body which runs in the
+// same goroutine as body, in depth-first order.  It does not
+// descend into function literals which are started with a go
+// statement, as their bodies run in other goroutines.
+func InspectGoroutine(body ast.Node, f func(ast.Node) bool) {
+	ast.Inspect(body, func(n ast.Node) bool {
+		if g, ok := n.(*ast.GoStmt); ok {
+			if !f(g) {
+				return false
+			}
+			// Arguments are evaluated in this goroutine
+			for _, arg := range g.Call.Args {
+				InspectGoroutine(arg, f)
+			}
+			if _, ok := ast.Unparen(g.Call.Fun).(*ast.FuncLit); !ok {
+				InspectGoroutine(g.Call.Fun, f)
+			}
+			return false
+		}
+		return f(n)
+	})
+}
+
+// Goroutine is the body of a function which is started with a go
+// statement somewhere in the package being analyzed.
+type Goroutine struct {
+	Body *ast.BlockStmt
+	Go   *ast.GoStmt // The first go statement which starts it
+}
+
+// Goroutines returns the bodies of every function literal started
+// with a go statement, and of every function declared in the
+// package which is started with a go statement.  Each body is
+// returned once, in the order it is first started.
+func Goroutines(pass *analysis.Pass) []Goroutine {
+	decls := make(map[*types.Func]*ast.FuncDecl)
+	for _, file := range pass.Files {
+		for _, decl := range file.Decls {
+			fd, ok := decl.(*ast.FuncDecl)
+			if !ok || fd.Body == nil {
+				continue
+			}
+			if fn, ok := pass.TypesInfo.Defs[fd.Name].(*types.Func); ok {
+				decls[fn] = fd
+			}
+		}
+	}
+
+	var goroutines []Goroutine
+	seen := make(map[*ast.BlockStmt]bool)
+	for _, file := range pass.Files {
+		ast.Inspect(file, func(n ast.Node) bool {
+			g, ok := n.(*ast.GoStmt)
+			if !ok {
+				return true
+			}
+			var body *ast.BlockStmt
+			if lit, ok := ast.Unparen(g.Call.Fun).(*ast.FuncLit); ok {
+				body = lit.Body
+			} else if fn, ok := typeutil.Callee(pass.TypesInfo, g.Call).(*types.Func); ok {
+				if fd := decls[fn.Origin()]; fd != nil {
+					body = fd.Body
+				}
+			}
+			if body != nil && !seen[body] {
+				seen[body] = true
+				goroutines = append(goroutines, Goroutine{body, g})
+			}
+			return true
+		})
+	}
+	return goroutines
+}
+
+// FuncBodies returns the body of every function declaration and
+// function literal in the package being analyzed.
+func FuncBodies(pass *analysis.Pass) []*ast.BlockStmt {
+	var bodies []*ast.BlockStmt
+	for _, file := range pass.Files {
+		ast.Inspect(file, func(n ast.Node) bool {
+			switch n := n.(type) {
+			case *ast.FuncDecl:
+				if n.Body != nil {
+					bodies = append(bodies, n.Body)
+				}
+			case *ast.FuncLit:
+				bodies = append(bodies, n.Body)
+			}
+			return true
+		})
+	}
+	return bodies
+}
+
+// RootBodies returns the body of every function declaration, and
+// of every function literal started with a go statement, in the
+// package being analyzed.  Walking each of these with
+// InspectGoroutine visits every node exactly once.
+func RootBodies(pass *analysis.Pass) []*ast.BlockStmt {
+	var bodies []*ast.BlockStmt
+	for _, file := range pass.Files {
+		ast.Inspect(file, func(n ast.Node) bool {
+			switch n := n.(type) {
+			case *ast.FuncDecl:
+				if n.Body != nil {
+					bodies = append(bodies, n.Body)
+				}
+			case *ast.GoStmt:
+				if lit, ok := ast.Unparen(n.Call.Fun).(*ast.FuncLit); ok {
+					bodies = append(bodies, lit.Body)
+				}
+			}
+			return true
+		})
+	}
+	return bodies
+}
+
+// ShortPos formats pos as file:line using only the base name of the
+// file, for referring to other locations in diagnostic messages.
+func ShortPos(fset *token.FileSet, pos token.Pos) string {
+	p := fset.Position(pos)
+	return fmt.Sprintf("%v:%v", filepath.Base(p.Filename), p.Line)
+}
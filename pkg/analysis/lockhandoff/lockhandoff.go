@@ -0,0 +1,151 @@
+/*
+Package lockhandoff defines an Analyzer which reports mutexes
+locked in one goroutine and unlocked in another.
+
+Go permits a sync.Mutex to be unlocked by a different goroutine
+than the one which locked it, but doing so hides which goroutine
+owns the lock, and a goroutine which fails to unlock it leaves
+the lock held forever.  The analyzer reports two halves of this
+pattern:
+
+  - a function which locks a mutex, never unlocks it, and then
+    starts a goroutine
+  - a function started with a go statement which unlocks a mutex
+    it never locked
+*/
+package lockhandoff
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+	"go/types"
+
+	"github.com/whatsacomputertho/go-learn/pkg/analysis/internal/analysisutil"
+	"golang.org/x/tools/go/analysis"
+)
+
+const Doc = `report mutexes locked in one goroutine and unlocked in another
+
+A goroutine should unlock every mutex it locks, ideally with a
+deferred call.  Handing a locked mutex over to another goroutine
+to unlock obscures which goroutine owns the lock.`
+
+var Analyzer = &analysis.Analyzer{
+	Name: "lockhandoff",
+	Doc:  Doc,
+	URL:  analysisutil.LessonURL("goroutines", "mutexes"),
+	Run:  run,
+}
+
+// unlocks maps each locking method to its unlocking method.
+var unlocks = map[string]string{
+	"(*sync.Mutex).Lock":    "Unlock",
+	"(*sync.RWMutex).Lock":  "Unlock",
+	"(*sync.RWMutex).RLock": "RUnlock",
+}
+
+// locks maps each unlocking method to its locking method.
+var locks = map[string]string{
+	"(*sync.Mutex).Unlock":    "Lock",
+	"(*sync.RWMutex).Unlock":  "Lock",
+	"(*sync.RWMutex).RUnlock": "RLock",
+}
+
+// lockCall is a call to a locking or unlocking method.
+type lockCall struct {
+	call   *ast.CallExpr
+	mutex  string // Receiver expression, such as "m" or "c.mu"
+	method string // Method name, such as "Lock" or "RUnlock"
+}
+
+func (c lockCall) String() string {
+	return c.mutex + "." + c.method + "()"
+}
+
+// goroutineCalls returns the locking and unlocking calls made by
+// body in its own goroutine, and the go statements it executes.
+func goroutineCalls(info *types.Info, body *ast.BlockStmt) (locked, unlocked []lockCall, gos []*ast.GoStmt) {
+	analysisutil.InspectGoroutine(body, func(n ast.Node) bool {
+		switch n := n.(type) {
+		case *ast.GoStmt:
+			gos = append(gos, n)
+		case *ast.CallExpr:
+			name := analysisutil.CalleeName(info, n)
+			_, isLock := unlocks[name]
+			_, isUnlock := locks[name]
+			if !isLock && !isUnlock {
+				return true
+			}
+			c := lockCall{
+				call:   n,
+				mutex:  types.ExprString(analysisutil.Receiver(n)),
+				method: n.Fun.(*ast.SelectorExpr).Sel.Name,
+			}
+			if isLock {
+				locked = append(locked, c)
+			} else {
+				unlocked = append(unlocked, c)
+			}
+		}
+		return true
+	})
+	return locked, unlocked, gos
+}
+
+// has reports whether calls contains a call to method on mutex.
+func has(calls []lockCall, mutex, method string) bool {
+	for _, c := range calls {
+		if c.mutex == mutex && c.method == method {
+			return true
+		}
+	}
+	return false
+}
+
+func run(pass *analysis.Pass) (interface{}, error) {
+	// Locks which are never unlocked before a goroutine starts
+	for _, body := range analysisutil.RootBodies(pass) {
+		locked, unlocked, gos := goroutineCalls(pass.TypesInfo, body)
+		for _, l := range locked {
+			unlock := unlocks[analysisutil.CalleeName(pass.TypesInfo, l.call)]
+			if has(unlocked, l.mutex, unlock) || !startsAfter(gos, l.call.Pos()) {
+				continue
+			}
+			pass.Report(analysis.Diagnostic{
+				Pos:     l.call.Pos(),
+				End:     l.call.End(),
+				Message: fmt.Sprintf("%v is never unlocked by this goroutine before starting another; unlock a mutex in the goroutine which locked it", l),
+				URL:     pass.Analyzer.URL,
+			})
+		}
+	}
+
+	// Goroutines which unlock a lock they never took
+	for _, g := range analysisutil.Goroutines(pass) {
+		locked, unlocked, _ := goroutineCalls(pass.TypesInfo, g.Body)
+		for _, u := range unlocked {
+			lock := locks[analysisutil.CalleeName(pass.TypesInfo, u.call)]
+			if has(locked, u.mutex, lock) {
+				continue
+			}
+			pass.Report(analysis.Diagnostic{
+				Pos:     u.call.Pos(),
+				End:     u.call.End(),
+				Message: fmt.Sprintf("%v unlocks a mutex which this goroutine (started at %v) never locked; lock it in this goroutine instead", u, analysisutil.ShortPos(pass.Fset, g.Go.Pos())),
+				URL:     pass.Analyzer.URL,
+			})
+		}
+	}
+	return nil, nil
+}
+
+// startsAfter reports whether any of the go statements follows pos.
+func startsAfter(gos []*ast.GoStmt, pos token.Pos) bool {
+	for _, g := range gos {
+		if g.Pos() > pos {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,12 @@
+package lockhandoff_test
+
+import (
+	"testing"
+
+	"github.com/whatsacomputertho/go-learn/pkg/analysis/lockhandoff"
+	"golang.org/x/tools/go/analysis/analysistest"
+)
+
+func TestAnalyzer(t *testing.T) {
+	analysistest.Run(t, analysistest.TestData(), lockhandoff.Analyzer, "a")
+}
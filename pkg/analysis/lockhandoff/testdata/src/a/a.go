@@ -0,0 +1,42 @@
+package a
+
+import "sync"
+
+var (
+	m  sync.RWMutex
+	mu sync.Mutex
+	wg sync.WaitGroup
+)
+
+// The lesson's first Mutexes example, which locks in main and
+// unlocks in the goroutines
+func handOff() {
+	for i := 0; i < 3; i++ {
+		wg.Add(2)
+		m.RLock() // want `m.RLock\(\) is never unlocked by this goroutine before starting another; unlock a mutex in the goroutine which locked it`
+		go sayHi()
+		m.Lock() // want `m.Lock\(\) is never unlocked`
+		go increment()
+	}
+	wg.Wait()
+}
+
+func sayHi() {
+	m.RUnlock() // want `m.RUnlock\(\) unlocks a mutex which this goroutine \(started at a.go:17\) never locked; lock it in this goroutine instead`
+	wg.Done()
+}
+
+func increment() {
+	m.Unlock() // want `m.Unlock\(\) unlocks a mutex which this goroutine \(started at a.go:19\) never locked`
+	wg.Done()
+}
+
+// Each goroutine unlocks the mutex it locked
+func owned() {
+	mu.Lock()
+	defer mu.Unlock()
+	go func() {
+		mu.Lock()
+		defer mu.Unlock()
+	}()
+}
@@ -0,0 +1,175 @@
+/*
+Package loopvar defines an Analyzer which reports closures that
+capture loop variables in code targeting Go versions before 1.22.
+
+Before Go 1.22, the variables declared by a for loop were shared
+by every iteration of the loop.  A function literal created in
+the loop body which refers to such a variable, whether run as a
+goroutine, deferred, or stored for later, sees whatever value
+the variable holds when the closure runs, which is usually its
+final value.  From Go 1.22 on, every iteration declares fresh
+variables, so the same code captures each iteration's value.
+
+The language version is taken from the file's //go:build
+constraint or the module's go directive.  Files targeting Go 1.22
+or later are not checked.
+*/
+package loopvar
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+	"go/types"
+	"go/version"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/analysis/passes/inspect"
+	"golang.org/x/tools/go/ast/inspector"
+)
+
+const Doc = `report closures capturing loop variables before Go 1.22
+
+Before Go 1.22, variables declared by a for loop are shared by
+every iteration.  A function literal in the loop body which
+refers to one of them observes the variable's value at the time
+the closure runs, rather than at the time it was created.`
+
+var Analyzer = &analysis.Analyzer{
+	Name:     "loopvar",
+	Doc:      Doc,
+	URL:      "https://github.com/whatsacomputertho/go-learn/tree/main/cmd/goroutines#loop-variable-capture",
+	Requires: []*analysis.Analyzer{inspect.Analyzer},
+	Run:      run,
+}
+
+// perIterationVersion is the first language version with
+// per-iteration loop variables.
+const perIterationVersion = "go1.22"
+
+func run(pass *analysis.Pass) (interface{}, error) {
+	ins := pass.ResultOf[inspect.Analyzer].(*inspector.Inspector)
+
+	ins.WithStack([]ast.Node{(*ast.FuncLit)(nil)}, func(n ast.Node, push bool, stack []ast.Node) bool {
+		if !push {
+			return true
+		}
+		file := stack[0].(*ast.File)
+		goVersion := fileVersion(pass, file)
+		if goVersion == "" || version.Compare(goVersion, perIterationVersion) >= 0 {
+			return true
+		}
+
+		lit := n.(*ast.FuncLit)
+		if calledImmediately(stack) {
+			return true
+		}
+		vars := enclosingLoopVars(pass.TypesInfo, stack)
+		if len(vars) == 0 {
+			return true
+		}
+
+		// Report each captured variable once, at its first use
+		reported := make(map[types.Object]bool)
+		ast.Inspect(lit.Body, func(n ast.Node) bool {
+			id, ok := n.(*ast.Ident)
+			if !ok {
+				return true
+			}
+			obj := pass.TypesInfo.Uses[id]
+			if obj == nil || !vars[obj] || reported[obj] {
+				return true
+			}
+			reported[obj] = true
+			pass.Report(analysis.Diagnostic{
+				Pos: id.Pos(),
+				End: id.End(),
+				Message: fmt.Sprintf("loop variable %v captured by func literal is shared by every iteration before Go 1.22 (file targets %v)",
+					id.Name, goVersion),
+				URL: pass.Analyzer.URL,
+			})
+			return true
+		})
+		return true
+	})
+	return nil, nil
+}
+
+// fileVersion returns the Go language version targeted by file, or
+// the empty string if it is unknown.
+func fileVersion(pass *analysis.Pass, file *ast.File) string {
+	if v := pass.TypesInfo.FileVersions[file]; v != "" {
+		return v
+	}
+	return pass.Pkg.GoVersion()
+}
+
+// calledImmediately reports whether the func literal at the top of
+// stack is called synchronously where it is declared, as in
+// func() { ... }().  Such a closure finishes running before the
+// loop variable can change.  Calls in go and defer statements do
+// not count, as they run later.
+func calledImmediately(stack []ast.Node) bool {
+	if len(stack) < 3 {
+		return false
+	}
+	call, ok := stack[len(stack)-2].(*ast.CallExpr)
+	if !ok || call.Fun != stack[len(stack)-1] {
+		return false
+	}
+	switch stack[len(stack)-3].(type) {
+	case *ast.GoStmt, *ast.DeferStmt:
+		return false
+	}
+	return true
+}
+
+// enclosingLoopVars returns the variables declared by the loops
+// whose bodies enclose the func literal at the top of stack, up to
+// the nearest enclosing function.  If the func literal is itself
+// nested in another func literal inside the loop, nothing is
+// returned, as the outer literal is reported instead.
+func enclosingLoopVars(info *types.Info, stack []ast.Node) map[types.Object]bool {
+	vars := make(map[types.Object]bool)
+	inLoop := false
+	for i := len(stack) - 2; i >= 0; i-- {
+		switch n := stack[i].(type) {
+		case *ast.FuncLit:
+			// Variables of loops outside this literal are
+			// captured by this literal too, and reported there
+			if !inLoop {
+				return nil
+			}
+			return vars
+		case *ast.FuncDecl:
+			return vars
+		case *ast.RangeStmt:
+			if n.Tok != token.DEFINE || stack[i+1] != n.Body {
+				continue
+			}
+			inLoop = true
+			addVar(info, vars, n.Key)
+			addVar(info, vars, n.Value)
+		case *ast.ForStmt:
+			init, ok := n.Init.(*ast.AssignStmt)
+			if !ok || init.Tok != token.DEFINE || stack[i+1] != n.Body {
+				continue
+			}
+			inLoop = true
+			for _, lhs := range init.Lhs {
+				addVar(info, vars, lhs)
+			}
+		}
+	}
+	return vars
+}
+
+func addVar(info *types.Info, vars map[types.Object]bool, expr ast.Expr) {
+	id, ok := expr.(*ast.Ident)
+	if !ok || id.Name == "_" {
+		return
+	}
+	if obj := info.Defs[id]; obj != nil {
+		vars[obj] = true
+	}
+}
@@ -0,0 +1,12 @@
+package loopvar_test
+
+import (
+	"testing"
+
+	"github.com/whatsacomputertho/go-learn/pkg/analysis/loopvar"
+	"golang.org/x/tools/go/analysis/analysistest"
+)
+
+func TestAnalyzer(t *testing.T) {
+	analysistest.Run(t, analysistest.TestData(), loopvar.Analyzer, "a", "b")
+}
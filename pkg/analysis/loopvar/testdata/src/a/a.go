@@ -0,0 +1,33 @@
+//go:build go1.21
+
+package a
+
+import "fmt"
+
+func capture() {
+	var prints []func()
+	for i := 0; i < 3; i++ {
+		prints = append(prints, func() { fmt.Print(i, i) }) // want `loop variable i captured by func literal is shared by every iteration before Go 1.22 \(file targets go1.21\)`
+	}
+	for _, print := range prints {
+		print()
+	}
+
+	for _, s := range []string{"a", "b"} {
+		go func() {
+			fmt.Print(s) // want `loop variable s captured`
+		}()
+		defer func() { fmt.Print(s) }() // want `loop variable s captured`
+
+		// Neither of these outlives the iteration
+		func() { fmt.Print(s) }()
+		go func(s string) { fmt.Print(s) }(s)
+	}
+
+	// Only the outer literal is reported
+	for i := 0; i < 3; i++ {
+		go func() {
+			func() { fmt.Print(i) }() // want `loop variable i captured`
+		}()
+	}
+}
@@ -0,0 +1,16 @@
+//go:build go1.22
+
+package b
+
+import "fmt"
+
+// From Go 1.22 on, every iteration declares a fresh i
+func capture() {
+	var prints []func()
+	for i := 0; i < 3; i++ {
+		prints = append(prints, func() { fmt.Print(i) })
+	}
+	for _, print := range prints {
+		print()
+	}
+}
@@ -0,0 +1,62 @@
+/*
+Package sleepsync defines an Analyzer which reports calls to
+time.Sleep used to wait for goroutines to finish.
+
+Sleeping after starting a goroutine guesses how long the
+goroutine takes to run.  The guess is wasteful when it is too
+long, and a race condition when it is too short.  A
+sync.WaitGroup or a channel waits exactly as long as needed.
+*/
+package sleepsync
+
+import (
+	"go/ast"
+	"go/token"
+
+	"github.com/whatsacomputertho/go-learn/pkg/analysis/internal/analysisutil"
+	"golang.org/x/tools/go/analysis"
+)
+
+const Doc = `report time.Sleep used to wait for goroutines
+
+A call to time.Sleep in a function which has already started a
+goroutine is most likely waiting for that goroutine to finish.
+Use a sync.WaitGroup or a channel instead.`
+
+var Analyzer = &analysis.Analyzer{
+	Name: "sleepsync",
+	Doc:  Doc,
+	URL:  analysisutil.LessonURL("goroutines", "waitgroups"),
+	Run:  run,
+}
+
+func run(pass *analysis.Pass) (interface{}, error) {
+	for _, body := range analysisutil.FuncBodies(pass) {
+		// Every function literal is checked on its own, so skip
+		// over them here
+		firstGo := token.NoPos
+		ast.Inspect(body, func(n ast.Node) bool {
+			switch n := n.(type) {
+			case *ast.FuncLit:
+				return false
+			case *ast.GoStmt:
+				if !firstGo.IsValid() {
+					firstGo = n.Pos()
+				}
+				return false
+			case *ast.CallExpr:
+				if !firstGo.IsValid() || analysisutil.CalleeName(pass.TypesInfo, n) != "time.Sleep" {
+					return true
+				}
+				pass.Report(analysis.Diagnostic{
+					Pos:     n.Pos(),
+					End:     n.End(),
+					Message: "time.Sleep used to wait for goroutines started above; use a sync.WaitGroup or a channel instead",
+					URL:     pass.Analyzer.URL,
+				})
+			}
+			return true
+		})
+	}
+	return nil, nil
+}
@@ -0,0 +1,12 @@
+package sleepsync_test
+
+import (
+	"testing"
+
+	"github.com/whatsacomputertho/go-learn/pkg/analysis/sleepsync"
+	"golang.org/x/tools/go/analysis/analysistest"
+)
+
+func TestAnalyzer(t *testing.T) {
+	analysistest.Run(t, analysistest.TestData(), sleepsync.Analyzer, "a")
+}
@@ -0,0 +1,37 @@
+package a
+
+import (
+	"sync"
+	"time"
+)
+
+func sleeps() {
+	go work()
+	time.Sleep(time.Second) // want `time.Sleep used to wait for goroutines started above; use a sync.WaitGroup or a channel instead`
+}
+
+func sleepsInLiteral() {
+	func() {
+		go work()
+		time.Sleep(time.Second) // want `time.Sleep used to wait for goroutines`
+	}()
+}
+
+// No goroutine has been started yet
+func sleepsFirst() {
+	time.Sleep(time.Millisecond)
+	go work()
+}
+
+// The goroutine's own sleep does not wait for anything
+func waits() {
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		time.Sleep(time.Millisecond)
+	}()
+	wg.Wait()
+}
+
+func work() {}
@@ -0,0 +1,33 @@
+package a
+
+import "sync"
+
+var wg sync.WaitGroup
+
+func addInside() {
+	var local sync.WaitGroup
+	go func() {
+		local.Add(1) // want `local.Add called inside the goroutine started at a.go:9; call it before the go statement so that Wait cannot return early`
+		defer local.Done()
+	}()
+	local.Wait()
+}
+
+func addBefore() {
+	var local sync.WaitGroup
+	local.Add(1)
+	go func() {
+		defer local.Done()
+	}()
+	local.Wait()
+}
+
+func startsWork() {
+	go work()
+	wg.Wait()
+}
+
+func work() {
+	wg.Add(1) // want `wg.Add called inside the goroutine started at a.go:26`
+	defer wg.Done()
+}
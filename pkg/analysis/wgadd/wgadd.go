@@ -0,0 +1,52 @@
+/*
+Package wgadd defines an Analyzer which reports calls to
+sync.WaitGroup.Add made inside the goroutine being waited for.
+
+A goroutine which calls wg.Add itself may not have been
+scheduled by the time its parent calls wg.Wait, in which case
+Wait sees a counter of zero and returns before the goroutine has
+even started.  Add must be called before the go statement.
+*/
+package wgadd
+
+import (
+	"fmt"
+	"go/ast"
+	"go/types"
+
+	"github.com/whatsacomputertho/go-learn/pkg/analysis/internal/analysisutil"
+	"golang.org/x/tools/go/analysis"
+)
+
+const Doc = `report sync.WaitGroup.Add called inside the spawned goroutine
+
+Calling wg.Add from inside the goroutine it accounts for races
+with the parent's call to wg.Wait, which may return before the
+goroutine has started.  Call wg.Add before the go statement.`
+
+var Analyzer = &analysis.Analyzer{
+	Name: "wgadd",
+	Doc:  Doc,
+	URL:  analysisutil.LessonURL("goroutines", "waitgroups"),
+	Run:  run,
+}
+
+func run(pass *analysis.Pass) (interface{}, error) {
+	for _, g := range analysisutil.Goroutines(pass) {
+		analysisutil.InspectGoroutine(g.Body, func(n ast.Node) bool {
+			call, ok := n.(*ast.CallExpr)
+			if !ok || analysisutil.CalleeName(pass.TypesInfo, call) != "(*sync.WaitGroup).Add" {
+				return true
+			}
+			wg := types.ExprString(analysisutil.Receiver(call))
+			pass.Report(analysis.Diagnostic{
+				Pos:     call.Pos(),
+				End:     call.End(),
+				Message: fmt.Sprintf("%v.Add called inside the goroutine started at %v; call it before the go statement so that Wait cannot return early", wg, analysisutil.ShortPos(pass.Fset, g.Go.Pos())),
+				URL:     pass.Analyzer.URL,
+			})
+			return true
+		})
+	}
+	return nil, nil
+}
@@ -0,0 +1,12 @@
+package wgadd_test
+
+import (
+	"testing"
+
+	"github.com/whatsacomputertho/go-learn/pkg/analysis/wgadd"
+	"golang.org/x/tools/go/analysis/analysistest"
+)
+
+func TestAnalyzer(t *testing.T) {
+	analysistest.Run(t, analysistest.TestData(), wgadd.Analyzer, "a")
+}
@@ -0,0 +1,189 @@
+/*
+Package clone makes deep copies of values.
+
+The maps-structs lesson shows that structs are value types, so
+assigning one copies it.  But the copy is shallow: a slice, map
+or pointer field is copied as a reference, and still shares its
+elements with the original.
+
+	yourPerson := myPerson
+	yourPerson.interests[0] = "Chess" // Changes myPerson's too
+
+DeepCopy follows every slice, map, pointer and interface within
+a value, including those in unexported fields, and copies what
+they refer to as well, so that nothing is shared.
+
+	yourPerson := clone.DeepCopy(myPerson)
+	yourPerson.interests[0] = "Chess" // myPerson is unchanged
+
+References shared within the original are shared in the same
+way within the copy, so cycles, such as a node which points to
+itself, are copied as cycles rather than followed forever.  The
+one exception is slices which only partly overlap, such as s and
+s[:2], which are copied to separate arrays.  A slice does not
+record where its array starts, so only slices with the same
+start and length are known to share one.
+
+Some values are not copied but shared, as copying them would be
+meaningless or break them:
+
+	channels and funcs         they have no contents to copy
+	unsafe.Pointer             its target's type is unknown
+	time.Time, *time.Location  they are immutable, and the time
+	                           package compares Locations by pointer
+*/
+package clone
+
+import (
+	"reflect"
+	"time"
+	"unsafe"
+)
+
+// DeepCopy returns a deep copy of v.
+func DeepCopy[T any](v T) T {
+	c := copier{seen: make(map[ref]reflect.Value)}
+	// Copy through a pointer, so that an interface type T keeps
+	// its static type rather than being replaced by v's dynamic
+	// type
+	src := reflect.ValueOf(&v).Elem()
+	dst := reflect.New(src.Type()).Elem()
+	dst.Set(c.copy(src))
+	return *dst.Addr().Interface().(*T)
+}
+
+// ref identifies a value which others may refer to, so that it is
+// copied once however many times it is referred to.  The type is
+// needed as well as the address, since a struct and its first
+// field share an address, and the length, since slices of
+// different lengths may share an address.
+type ref struct {
+	addr uintptr
+	typ  reflect.Type
+	len  int
+}
+
+type copier struct {
+	// seen maps each pointer, slice and map already copied to its
+	// copy
+	seen map[ref]reflect.Value
+}
+
+var (
+	timeType     = reflect.TypeOf(time.Time{})
+	locationType = reflect.TypeOf(&time.Location{})
+)
+
+// copy returns a deep copy of v.  v must not have been obtained
+// through unexported fields, so that its copy can be set.
+func (c *copier) copy(v reflect.Value) reflect.Value {
+	switch v.Type() {
+	case timeType, locationType:
+		return v
+	}
+
+	switch v.Kind() {
+	case reflect.Pointer:
+		if v.IsNil() {
+			return v
+		}
+		key := ref{v.Pointer(), v.Type(), 0}
+		if dst, ok := c.seen[key]; ok {
+			return dst
+		}
+		dst := reflect.New(v.Type().Elem())
+		c.seen[key] = dst
+		dst.Elem().Set(c.copy(v.Elem()))
+		return dst
+
+	case reflect.Slice:
+		if v.IsNil() {
+			return v
+		}
+		key := ref{v.Pointer(), v.Type(), v.Len()}
+		if dst, ok := c.seen[key]; ok {
+			return dst
+		}
+		dst := reflect.MakeSlice(v.Type(), v.Len(), v.Len())
+		c.seen[key] = dst
+		if !mayRefer(v.Type().Elem()) {
+			reflect.Copy(dst, v)
+			return dst
+		}
+		for i := 0; i < v.Len(); i++ {
+			dst.Index(i).Set(c.copy(v.Index(i)))
+		}
+		return dst
+
+	case reflect.Map:
+		if v.IsNil() {
+			return v
+		}
+		key := ref{v.Pointer(), v.Type(), 0}
+		if dst, ok := c.seen[key]; ok {
+			return dst
+		}
+		dst := reflect.MakeMapWithSize(v.Type(), v.Len())
+		c.seen[key] = dst
+		iter := v.MapRange()
+		for iter.Next() {
+			dst.SetMapIndex(c.copy(iter.Key()), c.copy(iter.Value()))
+		}
+		return dst
+
+	case reflect.Interface:
+		if v.IsNil() {
+			return v
+		}
+		dst := reflect.New(v.Type()).Elem()
+		dst.Set(c.copy(v.Elem()))
+		return dst
+
+	case reflect.Array:
+		dst := reflect.New(v.Type()).Elem()
+		dst.Set(v)
+		if !mayRefer(v.Type().Elem()) {
+			return dst
+		}
+		for i := 0; i < v.Len(); i++ {
+			dst.Index(i).Set(c.copy(v.Index(i)))
+		}
+		return dst
+
+	case reflect.Struct:
+		// Start from a shallow copy, which copies the unexported
+		// fields too, then replace each field which may refer to
+		// other values with a deep copy of it
+		dst := reflect.New(v.Type()).Elem()
+		dst.Set(v)
+		for i := 0; i < dst.NumField(); i++ {
+			f := dst.Field(i)
+			if !mayRefer(f.Type()) {
+				continue
+			}
+			if !f.CanSet() {
+				// Unexported fields can only be read and set
+				// through a pointer made with the unsafe package
+				f = reflect.NewAt(f.Type(), unsafe.Pointer(f.UnsafeAddr())).Elem()
+			}
+			f.Set(c.copy(f))
+		}
+		return dst
+	}
+
+	// Booleans, numbers and strings are immutable, and channels,
+	// funcs and unsafe pointers are shared
+	return v
+}
+
+// mayRefer reports whether values of type t may refer to other
+// values which a deep copy must also copy.
+func mayRefer(t reflect.Type) bool {
+	switch t.Kind() {
+	case reflect.Pointer, reflect.Slice, reflect.Map, reflect.Interface, reflect.Struct:
+		return t != timeType && t != locationType
+	case reflect.Array:
+		return mayRefer(t.Elem())
+	}
+	return false
+}
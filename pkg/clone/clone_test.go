@@ -0,0 +1,150 @@
+package clone_test
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/whatsacomputertho/go-learn/pkg/clone"
+)
+
+type Person struct {
+	Name      string
+	interests []string
+	friends   map[string]*Person
+}
+
+type Node struct {
+	Value int
+	Next  *Node
+}
+
+func TestDeepCopyIsIndependent(t *testing.T) {
+	alice := &Person{Name: "Alice", interests: []string{"Go"}}
+	bob := Person{
+		Name:      "Bob",
+		interests: []string{"Chess", "Go"},
+		friends:   map[string]*Person{"alice": alice},
+	}
+
+	c := clone.DeepCopy(bob)
+	if !reflect.DeepEqual(c, bob) {
+		t.Fatalf("DeepCopy() = %+v, want %+v", c, bob)
+	}
+
+	// Unexported fields, and the pointers held as map values, are
+	// copied rather than shared
+	c.interests[0] = "Poker"
+	c.friends["alice"].Name = "Alicia"
+	c.friends["alice"].interests[0] = "Rust"
+	c.friends["carol"] = &Person{Name: "Carol"}
+	if bob.interests[0] != "Chess" {
+		t.Errorf("changing the copy's interests changed the original's to %v", bob.interests)
+	}
+	if alice.Name != "Alice" || alice.interests[0] != "Go" {
+		t.Errorf("changing the copy's friend changed the original's to %+v", alice)
+	}
+	if len(bob.friends) != 1 {
+		t.Errorf("adding to the copy's friends added to the original's: %v", bob.friends)
+	}
+}
+
+func TestDeepCopyCycles(t *testing.T) {
+	self := &Node{Value: 1}
+	self.Next = self
+	ring := &Node{Value: 1, Next: &Node{Value: 2}}
+	ring.Next.Next = ring
+
+	c := clone.DeepCopy(self)
+	if c == self || c.Next != c {
+		t.Errorf("copying a node pointing to itself gave %p -> %p, want a new node pointing to itself", c, c.Next)
+	}
+	r := clone.DeepCopy(ring)
+	if r == ring || r.Next == ring.Next || r.Next.Next != r || r.Next.Value != 2 {
+		t.Errorf("copying a ring of two nodes did not give a new ring of two nodes")
+	}
+
+	m := map[string]any{}
+	m["self"] = m
+	mc := clone.DeepCopy(m)
+	if reflect.ValueOf(mc["self"]).Pointer() != reflect.ValueOf(mc).Pointer() {
+		t.Error("copying a map holding itself did not give a map holding itself")
+	}
+}
+
+func TestDeepCopySharing(t *testing.T) {
+	shared := &Node{Value: 1}
+	s := []int{1, 2, 3}
+	v := struct {
+		A, B *Node
+		S, T []int
+		Sub  []int
+	}{shared, shared, s, s, s[:2]}
+
+	c := clone.DeepCopy(v)
+	if c.A != c.B || c.A == shared {
+		t.Error("a pointer shared within the original is not shared within the copy")
+	}
+	c.S[0] = 10
+	if c.T[0] != 10 || s[0] != 1 {
+		t.Errorf("a slice shared within the original is not shared within the copy: %v, %v, %v", c.S, c.T, s)
+	}
+	// As documented, a subslice gets an array of its own
+	if c.Sub[0] != 1 {
+		t.Errorf("a subslice shares the copied array, which DeepCopy documents it does not: %v", c.Sub)
+	}
+}
+
+type Shape interface {
+	Area() int
+}
+
+type Square struct {
+	Side  int
+	Notes []string
+}
+
+func (s *Square) Area() int { return s.Side * s.Side }
+
+func TestDeepCopyInterfaces(t *testing.T) {
+	sq := &Square{Side: 2, Notes: []string{"small"}}
+	var shape Shape = sq
+	c := clone.DeepCopy(shape)
+	csq, ok := c.(*Square)
+	if !ok || csq == sq {
+		t.Fatalf("DeepCopy() = %#v, want a new *Square", c)
+	}
+	csq.Notes[0] = "large"
+	if sq.Notes[0] != "small" {
+		t.Error("changing the copied interface's value changed the original's")
+	}
+
+	// Values held in interfaces within other values are copied too
+	list := []any{[]int{1}, map[string]int{"a": 1}, nil}
+	lc := clone.DeepCopy(list)
+	lc[0].([]int)[0] = 2
+	lc[1].(map[string]int)["a"] = 2
+	if !reflect.DeepEqual(list, []any{[]int{1}, map[string]int{"a": 1}, nil}) {
+		t.Errorf("changing the copy changed the original to %v", list)
+	}
+}
+
+func TestDeepCopyUncopiedValues(t *testing.T) {
+	ch := make(chan int)
+	loc := time.FixedZone("X", 3600)
+	v := struct {
+		C   chan int
+		F   func() int
+		T   time.Time
+		Arr [2][]int
+	}{ch, func() int { return 1 }, time.Date(2024, 1, 1, 0, 0, 0, 0, loc), [2][]int{{1}, {2}}}
+
+	c := clone.DeepCopy(v)
+	if c.C != ch || c.F() != 1 || c.T.Location() != loc {
+		t.Error("channels, funcs and time Locations are not shared")
+	}
+	c.Arr[0][0] = 10
+	if v.Arr[0][0] != 1 {
+		t.Error("slices within arrays are shared")
+	}
+}
@@ -0,0 +1,220 @@
+package cmap_test
+
+import (
+	"errors"
+	"fmt"
+	"math/rand"
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/whatsacomputertho/go-learn/pkg/cmap"
+	"github.com/whatsacomputertho/go-learn/pkg/mapx"
+	"github.com/whatsacomputertho/go-learn/pkg/mapx/mapxtest"
+)
+
+// impls are the maps which are safe for concurrent use.  The
+// variants of a map, such as a Map made from its zero value, are
+// checked but not benchmarked.
+var impls = []struct {
+	name    string
+	variant bool
+	new     func() mapx.Map[int, int]
+}{
+	{"Map", false, func() mapx.Map[int, int] { return cmap.NewMap[int, int]() }},
+	{"Map/zero", true, func() mapx.Map[int, int] { return new(cmap.Map[int, int]) }},
+	{"Sharded", false, func() mapx.Map[int, int] { return cmap.NewSharded[int, int](0, cmap.HashInt[int]) }},
+	{"Sharded/one", true, func() mapx.Map[int, int] { return cmap.NewSharded[int, int](1, cmap.HashInt[int]) }},
+	{"SyncMap", false, func() mapx.Map[int, int] { return new(cmap.SyncMap[int, int]) }},
+}
+
+func TestMap(t *testing.T) {
+	for _, impl := range impls {
+		t.Run(impl.name, func(t *testing.T) {
+			if err := mapxtest.TestMap(impl.new, mapxtest.Unordered, 5000, 1); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
+
+// TestConcurrent shares each map between goroutines.  Each sets ops
+// keys of its own, reads them back and deletes every other one,
+// while also setting a few keys which every goroutine shares.  Once
+// they are done, the map must hold exactly the keys which were not
+// deleted, each with the value last set under it.  Run it with go
+// test -race to check that the maps are free of data races too.
+func TestConcurrent(t *testing.T) {
+	const goroutines, ops = 8, 1000
+	// Keys below shared are set by every goroutine, and goroutine
+	// g owns the ops keys from shared+g*ops
+	const shared = 4
+
+	// So that the goroutines run in parallel even on a single CPU
+	defer runtime.GOMAXPROCS(runtime.GOMAXPROCS(max(goroutines, runtime.GOMAXPROCS(0))))
+
+	for _, impl := range impls {
+		t.Run(impl.name, func(t *testing.T) {
+			m := impl.new()
+			var wg sync.WaitGroup
+			start := make(chan struct{})
+			errs := make([]error, goroutines)
+			wg.Add(goroutines)
+			for g := 0; g < goroutines; g++ {
+				go func(g int) {
+					defer wg.Done()
+					<-start
+					first := shared + g*ops
+					for k := first; k < first+ops; k++ {
+						m.Set(k, -k)
+						m.Set(k%shared, g)
+					}
+					for k := first; k < first+ops; k++ {
+						if v, ok := m.Get(k); !ok || v != -k {
+							errs[g] = fmt.Errorf("Get(%v) = %v, %v, want %v, true", k, v, ok, -k)
+							return
+						}
+						if k%2 == 1 && !m.Delete(k) {
+							errs[g] = fmt.Errorf("Delete(%v) = false, want true", k)
+							return
+						}
+					}
+				}(g)
+			}
+			close(start)
+			wg.Wait()
+			if err := errors.Join(errs...); err != nil {
+				t.Fatal(err)
+			}
+
+			want := make(map[int]bool)
+			for k := shared; k < shared+goroutines*ops; k++ {
+				want[k%shared] = true
+				if k%2 == 0 {
+					want[k] = true
+				}
+			}
+			if got := m.Len(); got != len(want) {
+				t.Errorf("Len() = %v, want %v", got, len(want))
+			}
+			m.Range(func(k, v int) bool {
+				switch {
+				case !want[k]:
+					t.Errorf("Range yielded key %v, which was deleted or never set", k)
+				case k < shared && (v < 0 || v >= goroutines):
+					t.Errorf("Range yielded %v: %v, which no goroutine set", k, v)
+				case k >= shared && v != -k:
+					t.Errorf("Range yielded %v: %v, want %v", k, v, -k)
+				default:
+					return true
+				}
+				return false
+			})
+		})
+	}
+}
+
+func TestUpdate(t *testing.T) {
+	const goroutines = 100
+	defer runtime.GOMAXPROCS(runtime.GOMAXPROCS(max(4, runtime.GOMAXPROCS(0))))
+
+	tests := []struct {
+		name string
+		m    interface {
+			Get(key string) (int, bool)
+			Update(key string, f func(value int, ok bool) int) int
+		}
+	}{
+		{"Map", cmap.NewMap[string, int]()},
+		{"Sharded", cmap.NewSharded[string, int](8, cmap.HashString)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var wg sync.WaitGroup
+			wg.Add(goroutines)
+			for i := 0; i < goroutines; i++ {
+				go func() {
+					defer wg.Done()
+					tt.m.Update("Ohio", func(n int, _ bool) int { return n + 1 })
+				}()
+			}
+			wg.Wait()
+			if got, ok := tt.m.Get("Ohio"); got != goroutines || !ok {
+				t.Errorf("Get() after %v concurrent Updates = %v, %v, want %v, true", goroutines, got, ok, goroutines)
+			}
+		})
+	}
+}
+
+func TestNewSharded(t *testing.T) {
+	defer runtime.GOMAXPROCS(runtime.GOMAXPROCS(3))
+	tests := []struct {
+		shards, want int
+	}{
+		{1, 1},
+		{5, 8},
+		{64, 64},
+		{0, 16}, // Four for each of 3 threads, rounded up
+		{-1, 16},
+	}
+	for _, tt := range tests {
+		s := cmap.NewSharded[string, int](tt.shards, cmap.HashString)
+		if got := s.Shards(); got != tt.want {
+			t.Errorf("NewSharded(%v).Shards() = %v, want %v", tt.shards, got, tt.want)
+		}
+	}
+}
+
+// The share of operations which read the map in each workload, as
+// a percentage
+const (
+	readHeavy  = 90
+	writeHeavy = 10
+)
+
+// BenchmarkReadHeavy and BenchmarkWriteHeavy share each map between
+// GOMAXPROCS goroutines, which the -cpu flag of go test sets.
+func BenchmarkReadHeavy(b *testing.B) {
+	benchmark(b, readHeavy)
+}
+
+func BenchmarkWriteHeavy(b *testing.B) {
+	benchmark(b, writeHeavy)
+}
+
+// benchmark measures a workload in which goroutines share a map of
+// 10000 keys, each reading a random key reads percent of the time
+// and setting one otherwise.  Every operation is timed, so the
+// result is the time per operation across all goroutines.
+func benchmark(b *testing.B, reads int) {
+	const n = 10000
+	keys := rand.New(rand.NewSource(1)).Perm(n)
+	for _, impl := range impls {
+		if impl.variant {
+			continue
+		}
+		b.Run(impl.name, func(b *testing.B) {
+			m := impl.new()
+			for _, k := range keys {
+				m.Set(k, k)
+			}
+			var seed atomic.Int64
+			b.ResetTimer()
+			b.RunParallel(func(pb *testing.PB) {
+				// Each goroutine draws its own keys, as a shared
+				// source of random numbers would need a lock of its
+				// own
+				rng := rand.New(rand.NewSource(seed.Add(1)))
+				for pb.Next() {
+					k := keys[rng.Intn(n)]
+					if rng.Intn(100) < reads {
+						m.Get(k)
+					} else {
+						m.Set(k, k)
+					}
+				}
+			})
+		})
+	}
+}
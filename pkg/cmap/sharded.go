@@ -0,0 +1,170 @@
+package cmap
+
+import (
+	"hash/maphash"
+	"math/bits"
+	"runtime"
+	"sync"
+	"unsafe"
+)
+
+// Sharded splits its keys between a number of shards, each a
+// builtin map guarded by its own sync.RWMutex, choosing a key's
+// shard by its hash.  Goroutines using keys in different shards
+// never wait for one another, so a Sharded map suffers much less
+// lock contention than a Map when it is written often.
+//
+// A Sharded map must be made with NewSharded.  Its zero value has
+// no shards and no hash, and is not usable.
+type Sharded[K comparable, V any] struct {
+	shards []shard[K, V]
+	mask   uint64
+	hash   func(K) uint64
+}
+
+// shard is one of the maps which make up a Sharded map.  It is
+// padded to a 64 byte cache line, so that locking one shard does
+// not slow down goroutines using its neighbours.
+type shard[K comparable, V any] struct {
+	mu sync.RWMutex
+	m  map[K]V
+	_  [64 - unsafe.Sizeof(sync.RWMutex{}) - unsafe.Sizeof(uintptr(0))]byte // A map is a pointer
+}
+
+// NewSharded returns an empty Sharded map with at least the given
+// number of shards, rounded up to a power of two, which places
+// keys by their hash.  If shards is less than one, there are four
+// shards for each of the GOMAXPROCS threads which may contend for
+// them.
+//
+// The hash must not be nil, and must spread keys evenly over all
+// 64 bits, as only its lowest bits choose a shard.  HashString and
+// HashInt are suitable for string and integer keys.
+func NewSharded[K comparable, V any](shards int, hash func(K) uint64) *Sharded[K, V] {
+	if shards < 1 {
+		shards = 4 * runtime.GOMAXPROCS(0)
+	}
+	n := 1 << bits.Len(uint(shards-1))
+	s := &Sharded[K, V]{shards: make([]shard[K, V], n), mask: uint64(n - 1), hash: hash}
+	for i := range s.shards {
+		s.shards[i].m = make(map[K]V)
+	}
+	return s
+}
+
+// Shards returns the number of shards.
+func (s *Sharded[K, V]) Shards() int {
+	return len(s.shards)
+}
+
+func (s *Sharded[K, V]) shard(key K) *shard[K, V] {
+	return &s.shards[s.hash(key)&s.mask]
+}
+
+// Get returns the value stored under key, and whether the key was
+// present.
+func (s *Sharded[K, V]) Get(key K) (V, bool) {
+	sh := s.shard(key)
+	sh.mu.RLock()
+	defer sh.mu.RUnlock()
+	v, ok := sh.m[key]
+	return v, ok
+}
+
+// Set stores value under key.
+func (s *Sharded[K, V]) Set(key K, value V) {
+	sh := s.shard(key)
+	sh.mu.Lock()
+	defer sh.mu.Unlock()
+	sh.m[key] = value
+}
+
+// Update replaces the value stored under key with f's result, as
+// Map's Update does.  Only key's shard is locked while f runs.
+func (s *Sharded[K, V]) Update(key K, f func(value V, ok bool) V) V {
+	sh := s.shard(key)
+	sh.mu.Lock()
+	defer sh.mu.Unlock()
+	v, ok := sh.m[key]
+	v = f(v, ok)
+	sh.m[key] = v
+	return v
+}
+
+// Delete removes key, and reports whether it was present.
+func (s *Sharded[K, V]) Delete(key K) bool {
+	sh := s.shard(key)
+	sh.mu.Lock()
+	defer sh.mu.Unlock()
+	_, ok := sh.m[key]
+	delete(sh.m, key)
+	return ok
+}
+
+// Len returns the number of keys.  The shards are counted one at
+// a time, so keys set or deleted meanwhile may or may not be
+// counted.
+func (s *Sharded[K, V]) Len() int {
+	n := 0
+	for i := range s.shards {
+		sh := &s.shards[i]
+		sh.mu.RLock()
+		n += len(sh.m)
+		sh.mu.RUnlock()
+	}
+	return n
+}
+
+// Range calls f for each key and value, stopping early if f
+// returns false.  Each shard is read locked while its keys are
+// visited, so f must not modify the map, and keys set or deleted
+// in other shards meanwhile may or may not be visited.
+func (s *Sharded[K, V]) Range(f func(key K, value V) bool) {
+	for i := range s.shards {
+		if !s.shards[i].rangeShard(f) {
+			return
+		}
+	}
+}
+
+// rangeShard calls f for each key and value in the shard, and
+// reports whether f asked to continue.
+func (sh *shard[K, V]) rangeShard(f func(key K, value V) bool) bool {
+	sh.mu.RLock()
+	defer sh.mu.RUnlock()
+	for k, v := range sh.m {
+		if !f(k, v) {
+			return false
+		}
+	}
+	return true
+}
+
+// seed is shared by every map hashing with HashString, so that a
+// string has the same hash in each of them.
+var seed = maphash.MakeSeed()
+
+// HashString hashes a string key for NewSharded.
+func HashString(key string) uint64 {
+	return maphash.String(seed, key)
+}
+
+// Integer is the set of integer types, which HashInt can hash.
+type Integer interface {
+	~int | ~int8 | ~int16 | ~int32 | ~int64 |
+		~uint | ~uint8 | ~uint16 | ~uint32 | ~uint64 | ~uintptr
+}
+
+// HashInt hashes an integer key for NewSharded.  It mixes the
+// integer's bits with the finalizer of the SplitMix64 generator,
+// so that consecutive keys are spread over different shards
+// rather than filling them in turn.
+func HashInt[K Integer](key K) uint64 {
+	x := uint64(key)
+	x ^= x >> 30
+	x *= 0xbf58476d1ce4e5b9
+	x ^= x >> 27
+	x *= 0x94d049bb133111eb
+	x ^= x >> 31
+	return x
+}
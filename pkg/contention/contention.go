@@ -0,0 +1,295 @@
+/*
+Package contention measures lock contention between readers and
+writers of shared state.
+
+The goroutines lesson guards a shared counter with a
+sync.RWMutex, where sayHiMutex reads under RLock and
+incrementMutex writes under Lock.  This package runs the same
+kind of workload with a configurable ratio of readers to
+writers against several ways of guarding the counter, with the
+runtime's mutex and block profilers enabled, and reports how
+much time goroutines spent waiting on one another.
+*/
+package contention
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"runtime"
+	"runtime/pprof"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"text/tabwriter"
+	"time"
+)
+
+// A Counter is a shared integer which is safe for concurrent use.
+// Each implementation guards the integer differently.
+type Counter interface {
+	Read() int64
+	Increment()
+}
+
+// Strategy names a way of guarding a Counter and constructs one.
+type Strategy struct {
+	Name string
+	New  func(work int) Counter
+}
+
+// Strategies are the guarding strategies compared by default.
+var Strategies = []Strategy{
+	{"sync.Mutex", func(work int) Counter { return &mutexCounter{work: work} }},
+	{"sync.RWMutex", func(work int) Counter { return &rwMutexCounter{work: work} }},
+	{"atomic.Int64", func(work int) Counter { return &atomicCounter{work: work} }},
+}
+
+// mutexCounter serializes readers and writers alike.
+type mutexCounter struct {
+	mu    sync.Mutex
+	value int64
+	work  int
+}
+
+func (c *mutexCounter) Read() int64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	spin(c.work)
+	return c.value
+}
+
+func (c *mutexCounter) Increment() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	spin(c.work)
+	c.value++
+}
+
+// rwMutexCounter lets readers share the lock while writers hold it
+// exclusively.
+type rwMutexCounter struct {
+	mu    sync.RWMutex
+	value int64
+	work  int
+}
+
+func (c *rwMutexCounter) Read() int64 {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	spin(c.work)
+	return c.value
+}
+
+func (c *rwMutexCounter) Increment() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	spin(c.work)
+	c.value++
+}
+
+// atomicCounter takes no lock at all.  The work is still performed
+// so that the comparison is fair, but it happens outside any
+// critical section.
+type atomicCounter struct {
+	value atomic.Int64
+	work  int
+}
+
+func (c *atomicCounter) Read() int64 {
+	spin(c.work)
+	return c.value.Load()
+}
+
+func (c *atomicCounter) Increment() {
+	spin(c.work)
+	c.value.Add(1)
+}
+
+// sink prevents the compiler from optimizing spin away.
+var sink atomic.Int64
+
+// spin simulates work performed while reading or writing.
+func spin(n int) {
+	x := int64(0)
+	for i := 0; i < n; i++ {
+		x += int64(i) ^ x
+	}
+	if x == -1 {
+		sink.Store(x)
+	}
+}
+
+// Config describes a workload.
+type Config struct {
+	Readers int // Goroutines which only read the counter
+	Writers int // Goroutines which only increment the counter
+	Ops     int // Operations performed by each goroutine
+	Work    int // Spin iterations performed per operation
+
+	// BlockProfileRate is the block profile rate restored when Run
+	// returns.  The runtime cannot report the rate in effect, so a
+	// program which profiles blocking itself should set this to
+	// the rate it passed to runtime.SetBlockProfileRate.
+	BlockProfileRate int
+}
+
+// Result holds the measurements for a single strategy.
+type Result struct {
+	Strategy    string
+	Duration    time.Duration
+	Ops         int
+	Value       int64         // Final counter value
+	MutexEvents int64         // Contended lock releases sampled
+	MutexDelay  time.Duration // Time waiters spent blocked on contended locks
+	BlockEvents int64         // Blocking events sampled
+	BlockDelay  time.Duration // Time goroutines spent blocked
+}
+
+// Throughput is the number of operations completed per second.
+func (r Result) Throughput() float64 {
+	if r.Duration == 0 {
+		return 0
+	}
+	return float64(r.Ops) / r.Duration.Seconds()
+}
+
+// Run executes the workload once per strategy, with every mutex
+// contention and blocking event profiled.  When Run returns, the
+// mutex profile fraction is restored, and the block profile rate
+// is set to cfg.BlockProfileRate.  If no strategies are given,
+// Strategies is used.
+func Run(cfg Config, strategies ...Strategy) ([]Result, error) {
+	if cfg.Readers < 0 || cfg.Writers < 0 || cfg.Ops < 0 || cfg.Work < 0 || cfg.BlockProfileRate < 0 {
+		return nil, fmt.Errorf("contention: invalid config %+v", cfg)
+	}
+	if len(strategies) == 0 {
+		strategies = Strategies
+	}
+
+	prevFraction := runtime.SetMutexProfileFraction(1)
+	runtime.SetBlockProfileRate(1)
+	defer runtime.SetMutexProfileFraction(prevFraction)
+	defer runtime.SetBlockProfileRate(cfg.BlockProfileRate)
+
+	hz := cyclesPerSecond()
+	results := make([]Result, 0, len(strategies))
+	for _, s := range strategies {
+		results = append(results, run(cfg, s, hz))
+	}
+	return results, nil
+}
+
+func run(cfg Config, s Strategy, hz float64) Result {
+	c := s.New(cfg.Work)
+	mutexBefore := sumProfile(runtime.MutexProfile)
+	blockBefore := sumProfile(runtime.BlockProfile)
+
+	var wg sync.WaitGroup
+	start := make(chan struct{})
+	wg.Add(cfg.Readers + cfg.Writers)
+	for i := 0; i < cfg.Readers; i++ {
+		go func() {
+			defer wg.Done()
+			<-start
+			for j := 0; j < cfg.Ops; j++ {
+				c.Read()
+			}
+		}()
+	}
+	for i := 0; i < cfg.Writers; i++ {
+		go func() {
+			defer wg.Done()
+			<-start
+			for j := 0; j < cfg.Ops; j++ {
+				c.Increment()
+			}
+		}()
+	}
+
+	t := time.Now()
+	close(start)
+	wg.Wait()
+	elapsed := time.Since(t)
+
+	mutexAfter := sumProfile(runtime.MutexProfile)
+	blockAfter := sumProfile(runtime.BlockProfile)
+	return Result{
+		Strategy:    s.Name,
+		Duration:    elapsed,
+		Ops:         (cfg.Readers + cfg.Writers) * cfg.Ops,
+		Value:       c.Read(),
+		MutexEvents: mutexAfter.count - mutexBefore.count,
+		MutexDelay:  cyclesToDuration(mutexAfter.cycles-mutexBefore.cycles, hz),
+		BlockEvents: blockAfter.count - blockBefore.count,
+		BlockDelay:  cyclesToDuration(blockAfter.cycles-blockBefore.cycles, hz),
+	}
+}
+
+type profileTotal struct {
+	count  int64
+	cycles int64
+}
+
+// sumProfile totals the records of a mutex or block profile across
+// every stack.
+func sumProfile(read func([]runtime.BlockProfileRecord) (int, bool)) profileTotal {
+	n, _ := read(nil)
+	for {
+		records := make([]runtime.BlockProfileRecord, n+16)
+		m, ok := read(records)
+		if !ok {
+			n = m
+			continue
+		}
+		var total profileTotal
+		for _, r := range records[:m] {
+			total.count += r.Count
+			total.cycles += r.Cycles
+		}
+		return total
+	}
+}
+
+// cyclesPerSecond reads the tick rate used by the runtime's mutex
+// profile from the header of its legacy text format, falling back
+// to treating cycles as nanoseconds.
+func cyclesPerSecond() float64 {
+	var buf bytes.Buffer
+	if err := pprof.Lookup("mutex").WriteTo(&buf, 1); err == nil {
+		s := bufio.NewScanner(&buf)
+		for s.Scan() {
+			if v, ok := strings.CutPrefix(s.Text(), "cycles/second="); ok {
+				if hz, err := strconv.ParseFloat(v, 64); err == nil && hz > 0 {
+					return hz
+				}
+			}
+		}
+	}
+	return 1e9
+}
+
+func cyclesToDuration(cycles int64, hz float64) time.Duration {
+	return time.Duration(float64(cycles) / hz * float64(time.Second))
+}
+
+// Fprint writes a contention report comparing results to w.
+func Fprint(w io.Writer, cfg Config, results []Result) {
+	fmt.Fprintf(w, "%v readers, %v writers, %v ops each, work %v\n", cfg.Readers, cfg.Writers, cfg.Ops, cfg.Work)
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(tw, "strategy\tduration\tops/sec\tcontended\tlock wait\tblocked\tblock wait\t")
+	for _, r := range results {
+		fmt.Fprintf(tw, "%v\t%v\t%.0f\t%v\t%v\t%v\t%v\t\n",
+			r.Strategy,
+			r.Duration.Round(time.Microsecond),
+			r.Throughput(),
+			r.MutexEvents,
+			r.MutexDelay.Round(time.Microsecond),
+			r.BlockEvents,
+			r.BlockDelay.Round(time.Microsecond),
+		)
+	}
+	tw.Flush()
+}
@@ -0,0 +1,150 @@
+/*
+Package counter extends the Incrementer interface from the
+interfaces lesson into a family of counters.
+
+The lesson's Incrementer has a single method, and its
+IntCounter increments an int with no synchronisation, so it
+must not be shared between goroutines.  The Incrementer declared
+here adds Add, Value and Reset, and is implemented by an
+IntCounter with the same limitation, an AtomicCounter which is
+safe for concurrent use, and a BoundedCounter which saturates at
+its bounds rather than overflowing.
+
+As the extended Incrementer includes Increment() int, every
+counter here also implements the lesson's Incrementer.
+*/
+package counter
+
+import (
+	"fmt"
+	"sync/atomic"
+)
+
+// Incrementer is implemented by counters.  Increment and Add return
+// the counter's value after the change.
+type Incrementer interface {
+	Increment() int
+	Add(n int) int
+	Value() int
+	Reset()
+}
+
+// IntCounter is the lesson's IntCounter, extended with the rest of
+// the Incrementer methods.  It is not safe for concurrent use, as
+// incrementing it reads and writes the int with no
+// synchronisation.
+type IntCounter int
+
+// Increment adds one to the counter.
+func (ic *IntCounter) Increment() int {
+	return ic.Add(1)
+}
+
+// Add adds n to the counter.
+func (ic *IntCounter) Add(n int) int {
+	*ic += IntCounter(n)
+	return int(*ic)
+}
+
+// Value returns the counter's value.
+func (ic *IntCounter) Value() int {
+	return int(*ic)
+}
+
+// Reset sets the counter to zero.
+func (ic *IntCounter) Reset() {
+	*ic = 0
+}
+
+// AtomicCounter is a counter which is safe for concurrent use.  Its
+// zero value is a counter at zero.
+type AtomicCounter struct {
+	value atomic.Int64
+}
+
+// Increment adds one to the counter.
+func (ac *AtomicCounter) Increment() int {
+	return ac.Add(1)
+}
+
+// Add adds n to the counter.
+func (ac *AtomicCounter) Add(n int) int {
+	return int(ac.value.Add(int64(n)))
+}
+
+// Value returns the counter's value.
+func (ac *AtomicCounter) Value() int {
+	return int(ac.value.Load())
+}
+
+// Reset sets the counter to zero.
+func (ac *AtomicCounter) Reset() {
+	ac.value.Store(0)
+}
+
+// BoundedCounter is a counter whose value stays between a minimum
+// and a maximum.  Changes which would take it past either bound
+// leave it at that bound instead.  It is safe for concurrent use.
+type BoundedCounter struct {
+	value    atomic.Int64
+	min, max int64
+}
+
+// NewBoundedCounter returns a BoundedCounter which stays between
+// min and max inclusive.  It starts at zero, or at the bound
+// nearest zero if zero is out of range.
+func NewBoundedCounter(min, max int) *BoundedCounter {
+	if min > max {
+		panic(fmt.Sprintf("counter: invalid bounds [%v, %v]", min, max))
+	}
+	bc := &BoundedCounter{min: int64(min), max: int64(max)}
+	bc.Reset()
+	return bc
+}
+
+// Bounds returns the counter's minimum and maximum.
+func (bc *BoundedCounter) Bounds() (min, max int) {
+	return int(bc.min), int(bc.max)
+}
+
+// Increment adds one to the counter, unless it is at its maximum.
+func (bc *BoundedCounter) Increment() int {
+	return bc.Add(1)
+}
+
+// Add adds n to the counter, saturating at its bounds.
+func (bc *BoundedCounter) Add(n int) int {
+	for {
+		old := bc.value.Load()
+		next := bc.clamp(old, int64(n))
+		// Another goroutine may have changed the value since it
+		// was loaded, in which case try again with the new value
+		if bc.value.CompareAndSwap(old, next) {
+			return int(next)
+		}
+	}
+}
+
+// clamp returns old+n limited to the counter's bounds, without
+// overflowing.
+func (bc *BoundedCounter) clamp(old, n int64) int64 {
+	sum := old + n
+	switch {
+	case n > 0 && sum < old:
+		return bc.max
+	case n < 0 && sum > old:
+		return bc.min
+	}
+	return min(max(sum, bc.min), bc.max)
+}
+
+// Value returns the counter's value.
+func (bc *BoundedCounter) Value() int {
+	return int(bc.value.Load())
+}
+
+// Reset sets the counter to zero, or to the bound nearest zero if
+// zero is out of range.
+func (bc *BoundedCounter) Reset() {
+	bc.value.Store(min(max(0, bc.min), bc.max))
+}
@@ -0,0 +1,145 @@
+package counter_test
+
+import (
+	"math"
+	"sync"
+	"testing"
+
+	"github.com/whatsacomputertho/go-learn/pkg/counter"
+	"github.com/whatsacomputertho/go-learn/pkg/counter/countertest"
+)
+
+var impls = []struct {
+	name string
+	new  func() counter.Incrementer
+	safe bool // Safe for concurrent use
+}{
+	{"IntCounter", func() counter.Incrementer { return new(counter.IntCounter) }, false},
+	{"AtomicCounter", func() counter.Incrementer { return new(counter.AtomicCounter) }, true},
+	{"BoundedCounter", func() counter.Incrementer { return counter.NewBoundedCounter(math.MinInt32, math.MaxInt32) }, true},
+}
+
+// An op is a call to one of the Incrementer methods, and the value
+// it should leave the counter at.
+type op struct {
+	name string // "Increment", "Add", "Reset"
+	n    int    // Argument to Add
+	want int
+}
+
+func apply(c counter.Incrementer, o op) int {
+	switch o.name {
+	case "Increment":
+		return c.Increment()
+	case "Add":
+		return c.Add(o.n)
+	case "Reset":
+		c.Reset()
+		return c.Value()
+	}
+	panic("unknown op " + o.name)
+}
+
+func TestIncrementer(t *testing.T) {
+	tests := []struct {
+		name string
+		ops  []op
+	}{
+		{"increment", []op{{"Increment", 0, 1}, {"Increment", 0, 2}, {"Increment", 0, 3}}},
+		{"add", []op{{"Add", 5, 5}, {"Add", -8, -3}, {"Add", 0, -3}}},
+		{"reset", []op{{"Add", 7, 7}, {"Reset", 0, 0}, {"Increment", 0, 1}}},
+	}
+	for _, impl := range impls {
+		for _, tt := range tests {
+			t.Run(impl.name+"/"+tt.name, func(t *testing.T) {
+				c := impl.new()
+				if got := c.Value(); got != 0 {
+					t.Fatalf("new counter Value() = %v, want 0", got)
+				}
+				for _, o := range tt.ops {
+					if got := apply(c, o); got != o.want {
+						t.Errorf("%v(%v) = %v, want %v", o.name, o.n, got, o.want)
+					}
+					if got := c.Value(); got != o.want {
+						t.Errorf("Value() after %v(%v) = %v, want %v", o.name, o.n, got, o.want)
+					}
+				}
+			})
+		}
+	}
+}
+
+func TestBoundedCounter(t *testing.T) {
+	tests := []struct {
+		name     string
+		min, max int
+		ops      []op
+	}{
+		{"saturates at max", -5, 5, []op{{"Add", 100, 5}, {"Increment", 0, 5}, {"Add", -1, 4}}},
+		{"saturates at min", -5, 5, []op{{"Add", -100, -5}, {"Add", -1, -5}, {"Increment", 0, -4}}},
+		{"starts at nearest bound", 3, 10, []op{{"Add", 0, 3}, {"Add", -1, 3}, {"Reset", 0, 3}}},
+		{"negative range", -10, -3, []op{{"Add", 0, -3}, {"Increment", 0, -3}, {"Add", -100, -10}}},
+		{"no overflow", math.MinInt64, math.MaxInt64, []op{
+			{"Add", math.MaxInt64, math.MaxInt64},
+			{"Increment", 0, math.MaxInt64},
+			{"Reset", 0, 0},
+			{"Add", math.MinInt64, math.MinInt64},
+			{"Add", -1, math.MinInt64},
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := counter.NewBoundedCounter(tt.min, tt.max)
+			for _, o := range tt.ops {
+				if got := apply(c, o); got != o.want {
+					t.Errorf("%v(%v) = %v, want %v", o.name, o.n, got, o.want)
+				}
+			}
+		})
+	}
+}
+
+func TestNewBoundedCounterInvalid(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("NewBoundedCounter(1, 0) did not panic")
+		}
+	}()
+	counter.NewBoundedCounter(1, 0)
+}
+
+// TestConcurrent shares each counter which is safe for concurrent
+// use between goroutines.  Run it with go test -race, which fails
+// the test if any of them has a data race.  The IntCounter is left
+// out, as it has one by design.
+func TestConcurrent(t *testing.T) {
+	for _, impl := range impls {
+		if !impl.safe {
+			continue
+		}
+		t.Run(impl.name, func(t *testing.T) {
+			if err := countertest.TestConcurrent(impl.new, 8, 1000); err != nil {
+				t.Error(err)
+			}
+
+			// Mix every method, as well as increments
+			c := impl.new()
+			var wg sync.WaitGroup
+			for g := 0; g < 8; g++ {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					for i := 0; i < 100; i++ {
+						c.Add(2)
+						c.Add(-1)
+						c.Value()
+					}
+				}()
+			}
+			wg.Wait()
+			if got := c.Value(); got != 800 {
+				t.Errorf("Value() = %v, want 800", got)
+			}
+		})
+	}
+}
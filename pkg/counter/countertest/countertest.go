@@ -0,0 +1,81 @@
+/*
+Package countertest holds the checks of counter.Incrementer
+implementations shared by the counter package's tests, the
+interfaces lesson and "golearn counters".
+
+Its checks report lost increments, which prove a counter unsafe
+to share.  They cannot prove one safe; for that, run the counter
+package's tests with go test -race.
+*/
+package countertest
+
+import (
+	"errors"
+	"fmt"
+	"runtime"
+	"sync"
+
+	"github.com/whatsacomputertho/go-learn/pkg/counter"
+)
+
+// TestIncrementer checks the counters returned by newCounter when
+// used by a single goroutine.  newCounter must return a new counter
+// at zero, which can hold values from -10 to 10.
+func TestIncrementer(newCounter func() counter.Incrementer) error {
+	c := newCounter()
+	var errs []error
+	check := func(op string, got, want int) {
+		if got != want {
+			errs = append(errs, fmt.Errorf("%v = %v, want %v", op, got, want))
+		}
+	}
+
+	check("Value()", c.Value(), 0)
+	check("Increment()", c.Increment(), 1)
+	check("Add(5)", c.Add(5), 6)
+	check("Add(-8)", c.Add(-8), -2)
+	check("Add(0)", c.Add(0), -2)
+	check("Value()", c.Value(), -2)
+	c.Reset()
+	check("Value() after Reset()", c.Value(), 0)
+	check("Increment() after Reset()", c.Increment(), 1)
+	return errors.Join(errs...)
+}
+
+// TestConcurrent shares a counter returned by newCounter between
+// goroutines, each of which increments it ops times, and checks
+// that the counter's final value counts every increment.  newCounter
+// must return a new counter at zero, which can hold a value of
+// goroutines*ops.
+//
+// GOMAXPROCS is raised to at least goroutines while the check runs,
+// so that they run in parallel rather than one after another.
+func TestConcurrent(newCounter func() counter.Incrementer, goroutines, ops int) error {
+	if prev := runtime.GOMAXPROCS(0); prev < goroutines {
+		runtime.GOMAXPROCS(goroutines)
+		defer runtime.GOMAXPROCS(prev)
+	}
+
+	c := newCounter()
+	var wg sync.WaitGroup
+	start := make(chan struct{})
+	wg.Add(goroutines)
+	for i := 0; i < goroutines; i++ {
+		go func() {
+			defer wg.Done()
+			<-start
+			for j := 0; j < ops; j++ {
+				c.Increment()
+			}
+		}()
+	}
+	close(start)
+	wg.Wait()
+
+	want := goroutines * ops
+	if got := c.Value(); got != want {
+		return fmt.Errorf("%v goroutines incremented %v times each: Value() = %v, want %v, %v increments lost",
+			goroutines, ops, got, want, want-got)
+	}
+	return nil
+}
@@ -0,0 +1,252 @@
+/*
+Package dataset loads key/value data, such as the state
+populations used by the maps-structs and looping lessons, from
+CSV and JSON files, and answers simple queries about it.
+
+A Dataset is an ordinary map, so everything the maps-structs
+lesson shows about maps applies to it.  Its methods add the
+operations which maps lack: iterating in a predictable order,
+finding the largest values, filtering and aggregating.
+
+	states, err := dataset.LoadFS(dataset.Builtin, "states.csv")
+	for _, e := range states.Top(3) {
+		fmt.Println(e.Key, e.Value)
+	}
+
+A CSV file holds one key and one value per record.  The first
+record is skipped as a header if its value is not an integer.
+
+	state,population
+	California,39250017
+
+A JSON file holds a single object whose values are integers.
+
+	{"California": 39250017}
+*/
+package dataset
+
+import (
+	"embed"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+//go:embed data
+var data embed.FS
+
+// Builtin holds the datasets shipped with this package, so that
+// the lessons can load them wherever they are run from.
+//
+//	states.csv   the populations of the seven largest US states
+//	states.json  the same populations as a JSON object
+var Builtin fs.FS = mustSub(data, "data")
+
+func mustSub(fsys fs.FS, dir string) fs.FS {
+	sub, err := fs.Sub(fsys, dir)
+	if err != nil {
+		panic(err)
+	}
+	return sub
+}
+
+// ErrFormat is returned when a file's format cannot be told from
+// its extension.
+var ErrFormat = errors.New("dataset: unknown format")
+
+// A Dataset maps keys to integer values.
+type Dataset map[string]int
+
+// An Entry is a single key/value pair of a Dataset.
+type Entry struct {
+	Key   string
+	Value int
+}
+
+// Load reads the dataset in the named file, choosing its format
+// from the file's extension, either .csv or .json.
+func Load(name string) (Dataset, error) {
+	return LoadFS(osFS{}, name)
+}
+
+// LoadFS is like Load, but reads the named file from fsys, such
+// as Builtin.
+func LoadFS(fsys fs.FS, name string) (Dataset, error) {
+	var read func(io.Reader) (Dataset, error)
+	switch strings.ToLower(path.Ext(name)) {
+	case ".csv":
+		read = ReadCSV
+	case ".json":
+		read = ReadJSON
+	default:
+		return nil, fmt.Errorf("%v: %w", name, ErrFormat)
+	}
+
+	f, err := fsys.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	d, err := read(f)
+	if err != nil {
+		return nil, fmt.Errorf("%v: %w", name, err)
+	}
+	return d, nil
+}
+
+// osFS opens names from the operating system, unlike os.DirFS
+// which only accepts relative, slash-separated paths.
+type osFS struct{}
+
+func (osFS) Open(name string) (fs.File, error) {
+	return os.Open(name)
+}
+
+// ReadCSV reads a dataset of key,value records from r.  A key
+// which appears more than once is an error.
+func ReadCSV(r io.Reader) (Dataset, error) {
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = 2
+	cr.TrimLeadingSpace = true
+
+	d := make(Dataset)
+	for line := 1; ; line++ {
+		record, err := cr.Read()
+		if err == io.EOF {
+			return d, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		key := record[0]
+		value, err := strconv.Atoi(strings.TrimSpace(record[1]))
+		if err != nil {
+			if line == 1 {
+				// A header
+				continue
+			}
+			return nil, fmt.Errorf("line %v: value of %q: %w", line, key, err)
+		}
+		if _, ok := d[key]; ok {
+			return nil, fmt.Errorf("line %v: duplicate key %q", line, key)
+		}
+		d[key] = value
+	}
+}
+
+// ReadJSON reads a dataset from a JSON object in r.
+func ReadJSON(r io.Reader) (Dataset, error) {
+	var d Dataset
+	if err := json.NewDecoder(r).Decode(&d); err != nil {
+		return nil, err
+	}
+	if d == nil {
+		// The object was null
+		d = make(Dataset)
+	}
+	return d, nil
+}
+
+// Keys returns the keys of d in sorted order.  Ranging over a map
+// visits its keys in an unspecified order, which changes from run
+// to run, so sorting them is the usual way to iterate predictably.
+func (d Dataset) Keys() []string {
+	keys := make([]string, 0, len(d))
+	for k := range d {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// Entries returns the entries of d sorted by key.
+func (d Dataset) Entries() []Entry {
+	entries := make([]Entry, 0, len(d))
+	for _, k := range d.Keys() {
+		entries = append(entries, Entry{k, d[k]})
+	}
+	return entries
+}
+
+// ByValue returns the entries of d sorted by value, largest
+// first.  Entries with equal values are sorted by key.
+func (d Dataset) ByValue() []Entry {
+	entries := d.Entries()
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].Value > entries[j].Value
+	})
+	return entries
+}
+
+// Top returns the n entries of d with the largest values, largest
+// first, or every entry if d holds fewer than n.
+func (d Dataset) Top(n int) []Entry {
+	entries := d.ByValue()
+	if n < 0 {
+		n = 0
+	}
+	if n < len(entries) {
+		entries = entries[:n]
+	}
+	return entries
+}
+
+// Filter returns a new dataset holding the entries of d for which
+// keep returns true.
+func (d Dataset) Filter(keep func(e Entry) bool) Dataset {
+	filtered := make(Dataset)
+	for k, v := range d {
+		if keep(Entry{k, v}) {
+			filtered[k] = v
+		}
+	}
+	return filtered
+}
+
+// Aggregate combines the entries of d in key order, starting from
+// init, and returns the result.
+//
+//	sum := d.Aggregate(0, func(acc int, e dataset.Entry) int { return acc + e.Value })
+func (d Dataset) Aggregate(init int, combine func(acc int, e Entry) int) int {
+	acc := init
+	for _, e := range d.Entries() {
+		acc = combine(acc, e)
+	}
+	return acc
+}
+
+// Summary describes the values of a dataset.
+type Summary struct {
+	Count    int
+	Sum      int
+	Mean     float64
+	Min, Max Entry
+}
+
+// Summarize returns a summary of the values of d.  The Min and
+// Max of an empty dataset are zero Entries.  When several entries
+// share the smallest or largest value, the first by key is used.
+func (d Dataset) Summarize() Summary {
+	s := Summary{Count: len(d)}
+	for i, e := range d.Entries() {
+		s.Sum += e.Value
+		if i == 0 || e.Value < s.Min.Value {
+			s.Min = e
+		}
+		if i == 0 || e.Value > s.Max.Value {
+			s.Max = e
+		}
+	}
+	if s.Count > 0 {
+		s.Mean = float64(s.Sum) / float64(s.Count)
+	}
+	return s
+}
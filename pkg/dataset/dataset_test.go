@@ -0,0 +1,140 @@
+package dataset_test
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/whatsacomputertho/go-learn/pkg/dataset"
+)
+
+var sample = dataset.Dataset{"b": 3, "a": 3, "c": 10, "d": -1}
+
+func TestOrdering(t *testing.T) {
+	tests := []struct {
+		name string
+		got  []dataset.Entry
+		want []dataset.Entry
+	}{
+		{"Entries", sample.Entries(), []dataset.Entry{{"a", 3}, {"b", 3}, {"c", 10}, {"d", -1}}},
+		// Equal values keep their key order
+		{"ByValue", sample.ByValue(), []dataset.Entry{{"c", 10}, {"a", 3}, {"b", 3}, {"d", -1}}},
+		{"Top", sample.Top(2), []dataset.Entry{{"c", 10}, {"a", 3}}},
+		{"TopAll", sample.Top(10), sample.ByValue()},
+		{"TopNone", sample.Top(-1), []dataset.Entry{}},
+		{"Empty", dataset.Dataset{}.ByValue(), []dataset.Entry{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if !reflect.DeepEqual(tt.got, tt.want) {
+				t.Errorf("got %v, want %v", tt.got, tt.want)
+			}
+		})
+	}
+	if got, want := sample.Keys(), []string{"a", "b", "c", "d"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Keys() = %v, want %v", got, want)
+	}
+}
+
+func TestFilterAndAggregate(t *testing.T) {
+	positive := sample.Filter(func(e dataset.Entry) bool { return e.Value > 0 })
+	if want := (dataset.Dataset{"a": 3, "b": 3, "c": 10}); !reflect.DeepEqual(positive, want) {
+		t.Errorf("Filter() = %v, want %v", positive, want)
+	}
+	if _, ok := sample["d"]; !ok {
+		t.Error("Filter() modified the dataset")
+	}
+
+	// Entries are combined in key order
+	keys := sample.Aggregate(0, func(acc int, e dataset.Entry) int { return acc*10 + int(e.Key[0]-'a') })
+	if keys != 123 {
+		t.Errorf("Aggregate() combined the keys in the order %04d, want 0123", keys)
+	}
+}
+
+func TestSummarize(t *testing.T) {
+	tests := []struct {
+		name string
+		d    dataset.Dataset
+		want dataset.Summary
+	}{
+		{"Sample", sample, dataset.Summary{Count: 4, Sum: 15, Mean: 3.75, Min: dataset.Entry{"d", -1}, Max: dataset.Entry{"c", 10}}},
+		// Ties go to the first key
+		{"Ties", dataset.Dataset{"b": 3, "a": 3}, dataset.Summary{Count: 2, Sum: 6, Mean: 3, Min: dataset.Entry{"a", 3}, Max: dataset.Entry{"a", 3}}},
+		{"Empty", dataset.Dataset{}, dataset.Summary{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.d.Summarize(); got != tt.want {
+				t.Errorf("Summarize() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoadFS(t *testing.T) {
+	fsys := fstest.MapFS{
+		"header.csv":   {Data: []byte("state,population\nOhio, 11614373\n\"New York\",19745289\n")},
+		"noheader.CSV": {Data: []byte("a,1\nb,2\n")},
+		"bad.csv":      {Data: []byte("a,1\nb,two\n")},
+		"dup.csv":      {Data: []byte("a,1\na,2\n")},
+		"fields.csv":   {Data: []byte("a,1,2\n")},
+		"states.json":  {Data: []byte(`{"Ohio": 11614373}`)},
+		"null.json":    {Data: []byte(`null`)},
+		"float.json":   {Data: []byte(`{"a": 1.5}`)},
+		"states.txt":   {Data: []byte("a,1\n")},
+	}
+	tests := []struct {
+		name    string
+		want    dataset.Dataset
+		wantErr string
+	}{
+		{"header.csv", dataset.Dataset{"Ohio": 11614373, "New York": 19745289}, ""},
+		{"noheader.CSV", dataset.Dataset{"a": 1, "b": 2}, ""},
+		{"bad.csv", nil, `bad.csv: line 2: value of "b"`},
+		{"dup.csv", nil, `dup.csv: line 2: duplicate key "a"`},
+		{"fields.csv", nil, "wrong number of fields"},
+		{"states.json", dataset.Dataset{"Ohio": 11614373}, ""},
+		{"null.json", dataset.Dataset{}, ""},
+		{"float.json", nil, "float.json: json"},
+		{"states.txt", nil, "unknown format"},
+		{"missing.csv", nil, "file does not exist"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d, err := dataset.LoadFS(fsys, tt.name)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("LoadFS() = %v, want an error containing %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(d, tt.want) {
+				t.Errorf("LoadFS() = %v, want %v", d, tt.want)
+			}
+		})
+	}
+
+	if _, err := dataset.LoadFS(fsys, "states.txt"); !errors.Is(err, dataset.ErrFormat) {
+		t.Errorf("LoadFS() of a .txt file = %v, want %v", err, dataset.ErrFormat)
+	}
+}
+
+func TestBuiltin(t *testing.T) {
+	csv, err := dataset.LoadFS(dataset.Builtin, "states.csv")
+	if err != nil {
+		t.Fatal(err)
+	}
+	json, err := dataset.LoadFS(dataset.Builtin, "states.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(csv) != 7 || !reflect.DeepEqual(csv, json) {
+		t.Errorf("states.csv holds %v, states.json holds %v, want the same seven states", csv, json)
+	}
+}
@@ -0,0 +1,240 @@
+/*
+Package defaults fills the zero-valued fields of a struct from
+their default tags, so that the maps-structs lesson's Dog{}
+becomes Dog{IsGood: true}.
+
+	type Dog struct {
+		IsGood bool   `default:"true"`
+		Breed  string `required:"true" max:"100"`
+	}
+
+A default is parsed according to the type of its field.
+
+	string                    used as is
+	bool                      strconv.ParseBool, as in "true"
+	ints, uints               strconv.ParseInt and ParseUint, as in "42" or "0x2a"
+	floats                    strconv.ParseFloat, as in "1.5"
+	time.Duration             time.ParseDuration, as in "1m30s"
+	encoding.TextUnmarshaler  its UnmarshalText method, as time.Time's RFC 3339
+	slices                    comma separated elements, each parsed as above
+	                          once surrounding spaces are removed
+
+Apply recurses into nested and embedded structs, such as the
+Animal embedded in the lesson's Bird, into non-nil pointers, and
+into the values held by interfaces, and into the elements of
+slices, arrays and maps, so that their fields are given their
+defaults too.  Each pointer and map is descended into once, so
+cyclic values are safe.  Unexported fields are skipped.
+*/
+package defaults
+
+import (
+	"encoding"
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrNotStructPointer is returned when Apply is given anything
+// other than a non-nil pointer to a struct.
+var ErrNotStructPointer = errors.New("defaults: want a non-nil pointer to a struct")
+
+// A FieldError describes a default which could not be applied.
+type FieldError struct {
+	Path    string // Path of the field from the struct given to Apply
+	Default string // The default which could not be applied
+	Err     error
+}
+
+func (e *FieldError) Error() string {
+	return fmt.Sprintf("%v: invalid default %q: %v", e.Path, e.Default, e.Err)
+}
+
+func (e *FieldError) Unwrap() error {
+	return e.Err
+}
+
+// Apply sets every zero-valued field of the struct which ptr points
+// to, and of the structs nested in it, to its default.  Fields whose
+// defaults cannot be parsed are left unchanged, and reported
+// together as FieldErrors joined by errors.Join.
+func Apply(ptr any) error {
+	v := reflect.ValueOf(ptr)
+	if v.Kind() != reflect.Pointer || v.IsNil() || v.Elem().Kind() != reflect.Struct {
+		return ErrNotStructPointer
+	}
+	a := &applier{seen: make(map[visit]bool)}
+	a.apply(v, "")
+	return errors.Join(a.errs...)
+}
+
+// applier holds the state of a single call to Apply.
+type applier struct {
+	errs []error
+	seen map[visit]bool // The pointers and maps already descended into
+}
+
+// visit identifies a pointer or map by its address and type, as a
+// struct and its first field share an address.
+type visit struct {
+	ptr uintptr
+	typ reflect.Type
+}
+
+// enter reports whether the pointer or map v has not been
+// descended into before, and marks it as having been, so that
+// cyclic values such as a node whose Next field points to itself
+// are walked only once.
+func (a *applier) enter(v reflect.Value) bool {
+	k := visit{v.Pointer(), v.Type()}
+	if a.seen[k] {
+		return false
+	}
+	a.seen[k] = true
+	return true
+}
+
+func (a *applier) applyStruct(v reflect.Value, prefix string) {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if !f.IsExported() {
+			continue
+		}
+		path := f.Name
+		if prefix != "" {
+			path = prefix + "." + f.Name
+		}
+		field := v.Field(i)
+		if def, ok := f.Tag.Lookup("default"); ok && field.IsZero() {
+			if err := Set(field, def); err != nil {
+				a.errs = append(a.errs, &FieldError{Path: path, Default: def, Err: err})
+			}
+		}
+		a.apply(field, path)
+	}
+}
+
+// apply descends into v, applying the defaults of any structs it
+// holds.
+func (a *applier) apply(v reflect.Value, path string) {
+	switch v.Kind() {
+	case reflect.Struct:
+		a.applyStruct(v, path)
+	case reflect.Pointer:
+		if !v.IsNil() && a.enter(v) {
+			a.apply(v.Elem(), path)
+		}
+	case reflect.Interface:
+		if v.IsNil() {
+			return
+		}
+		elem := v.Elem()
+		if elem.Kind() == reflect.Pointer {
+			a.apply(elem, path)
+			return
+		}
+		// The value held by an interface cannot be set in place,
+		// so fill a copy and store it back
+		cp := reflect.New(elem.Type()).Elem()
+		cp.Set(elem)
+		a.apply(cp, path)
+		v.Set(cp)
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			a.apply(v.Index(i), fmt.Sprintf("%v[%v]", path, i))
+		}
+	case reflect.Map:
+		if v.IsNil() || !a.enter(v) {
+			return
+		}
+		for _, k := range sortedKeys(v) {
+			// Map elements cannot be set in place, so fill a copy
+			// and store it back
+			elem := reflect.New(v.Type().Elem()).Elem()
+			elem.Set(v.MapIndex(k))
+			a.apply(elem, fmt.Sprintf("%v[%v]", path, k))
+			v.SetMapIndex(k, elem)
+		}
+	}
+}
+
+// sortedKeys returns the keys of the map m sorted by their printed
+// form, so that maps are walked in a repeatable order.
+func sortedKeys(m reflect.Value) []reflect.Value {
+	keys := m.MapKeys()
+	sort.Slice(keys, func(i, j int) bool {
+		return fmt.Sprint(keys[i]) < fmt.Sprint(keys[j])
+	})
+	return keys
+}
+
+var (
+	durationType        = reflect.TypeOf(time.Duration(0))
+	textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+)
+
+// Set parses s according to the type of v, as described in the
+// package documentation, and stores the result in v, which must be
+// settable.
+func Set(v reflect.Value, s string) error {
+	if reflect.PointerTo(v.Type()).Implements(textUnmarshalerType) {
+		return v.Addr().Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(s))
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(s)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(s)
+		if err != nil {
+			return err
+		}
+		v.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if v.Type() == durationType {
+			d, err := time.ParseDuration(s)
+			if err != nil {
+				return err
+			}
+			v.SetInt(int64(d))
+			return nil
+		}
+		n, err := strconv.ParseInt(s, 0, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(s, 0, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(s, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetFloat(f)
+	case reflect.Slice:
+		var parts []string
+		if s != "" {
+			parts = strings.Split(s, ",")
+		}
+		slice := reflect.MakeSlice(v.Type(), len(parts), len(parts))
+		for i, part := range parts {
+			if err := Set(slice.Index(i), strings.TrimSpace(part)); err != nil {
+				return fmt.Errorf("element %v: %w", i, err)
+			}
+		}
+		v.Set(slice)
+	default:
+		return fmt.Errorf("cannot parse a %v", v.Type())
+	}
+	return nil
+}
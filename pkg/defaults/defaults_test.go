@@ -0,0 +1,117 @@
+package defaults_test
+
+import (
+	"errors"
+	"reflect"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/whatsacomputertho/go-learn/pkg/defaults"
+)
+
+type Dog struct {
+	IsGood bool   `default:"true"`
+	Breed  string `default:"mutt"`
+}
+
+type Holder struct {
+	Pet any
+}
+
+type Node struct {
+	Name string `default:"node"`
+	Next *Node
+}
+
+type Config struct {
+	Timeout time.Duration `default:"1m30s"`
+	Retries uint8         `default:"3"`
+	Tags    []string      `default:"a, b"`
+	Dogs    map[string]Dog
+	Pack    []Dog
+	private string `default:"ignored"`
+}
+
+func TestApply(t *testing.T) {
+	tests := []struct {
+		name string
+		ptr  any
+		want any
+	}{
+		{"Zero", &Dog{}, &Dog{IsGood: true, Breed: "mutt"}},
+		{"Set", &Dog{Breed: "lab"}, &Dog{IsGood: true, Breed: "lab"}},
+		{"Interface", &Holder{Pet: Dog{}}, &Holder{Pet: Dog{IsGood: true, Breed: "mutt"}}},
+		{"InterfacePointer", &Holder{Pet: &Dog{}}, &Holder{Pet: &Dog{IsGood: true, Breed: "mutt"}}},
+		{"InterfaceArray", &Holder{Pet: [1]Dog{}}, &Holder{Pet: [1]Dog{{IsGood: true, Breed: "mutt"}}}},
+		{"Parsed", &Config{}, &Config{Timeout: 90 * time.Second, Retries: 3, Tags: []string{"a", "b"}}},
+		{"Elements",
+			&Config{Dogs: map[string]Dog{"rex": {}}, Pack: []Dog{{Breed: "lab"}}},
+			&Config{Timeout: 90 * time.Second, Retries: 3, Tags: []string{"a", "b"},
+				Dogs: map[string]Dog{"rex": {IsGood: true, Breed: "mutt"}},
+				Pack: []Dog{{IsGood: true, Breed: "lab"}}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := defaults.Apply(tt.ptr); err != nil {
+				t.Fatalf("Apply() = %v, want nil", err)
+			}
+			if !reflect.DeepEqual(tt.ptr, tt.want) {
+				t.Errorf("Apply() set %+v, want %+v", tt.ptr, tt.want)
+			}
+		})
+	}
+}
+
+func TestApplyCycle(t *testing.T) {
+	n := &Node{}
+	n.Next = n
+	if err := defaults.Apply(n); err != nil {
+		t.Fatalf("Apply() = %v, want nil", err)
+	}
+	if n.Name != "node" || n.Next != n {
+		t.Errorf("Apply() set %+v, want Name %q and Next unchanged", n, "node")
+	}
+
+	m := map[string]any{}
+	m["self"] = m
+	h := &Holder{Pet: m}
+	if err := defaults.Apply(h); err != nil {
+		t.Fatalf("Apply() = %v, want nil", err)
+	}
+}
+
+func TestApplyErrors(t *testing.T) {
+	type Bad struct {
+		Count int           `default:"many"`
+		Wait  time.Duration `default:"soon"`
+		Ch    chan int      `default:"1"`
+		Dogs  []Dog
+	}
+	bad := &Bad{Count: 0, Dogs: []Dog{{}}}
+	err := defaults.Apply(bad)
+
+	var paths []string
+	for _, e := range err.(interface{ Unwrap() []error }).Unwrap() {
+		var fe *defaults.FieldError
+		if !errors.As(e, &fe) {
+			t.Fatalf("Apply() returned %T, want *FieldError", e)
+		}
+		paths = append(paths, fe.Path)
+	}
+	if want := []string{"Count", "Wait", "Ch"}; !reflect.DeepEqual(paths, want) {
+		t.Errorf("Apply() failed fields %q, want %q", paths, want)
+	}
+	if !errors.Is(err, strconv.ErrSyntax) {
+		t.Errorf("Apply() = %v, want it to wrap %v", err, strconv.ErrSyntax)
+	}
+	if !bad.Dogs[0].IsGood {
+		t.Error("Apply() stopped at the first error, want the remaining defaults applied")
+	}
+
+	for _, ptr := range []any{nil, Dog{}, (*Dog)(nil), new(int)} {
+		if err := defaults.Apply(ptr); err != defaults.ErrNotStructPointer {
+			t.Errorf("Apply(%#v) = %v, want %v", ptr, err, defaults.ErrNotStructPointer)
+		}
+	}
+}
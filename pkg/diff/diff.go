@@ -0,0 +1,369 @@
+/*
+Package diff compares two values field by field, and reports
+exactly where they differ.
+
+The maps-structs lesson copies a Person, changes the copy, and
+prints both to show which of them changed.  Diff does the
+comparing for us, and reports each difference with the path of
+the field where it was found.
+
+	report := diff.Diff(myPerson, yourPerson)
+	fmt.Print(report)
+	// Name: "Joe" != "Hank"
+	// interests[0]: "Programming" != "Chess"
+
+Diff walks into struct fields, including unexported and embedded
+fields, and into the elements of slices, arrays and maps, the
+values behind pointers, and the dynamic values of interfaces.
+Like reflect.DeepEqual, it treats nil and empty slices and maps
+as different, and funcs as different unless both are nil.  A
+type with an Equal method, such as time.Time, is compared with
+it rather than field by field.
+*/
+package diff
+
+import (
+	"fmt"
+	"io"
+	"reflect"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"unsafe"
+)
+
+// A Change is the kind of a difference.
+type Change int
+
+const (
+	// Modified means the value at the path differs.
+	Modified Change = iota
+
+	// Added means the path is present in b but not in a, such
+	// as a slice element beyond a's length or a map key
+	// missing from a.
+	Added
+
+	// Removed means the path is present in a but not in b.
+	Removed
+)
+
+func (c Change) String() string {
+	switch c {
+	case Modified:
+		return "modified"
+	case Added:
+		return "added"
+	case Removed:
+		return "removed"
+	}
+	return fmt.Sprintf("Change(%d)", int(c))
+}
+
+// A Difference is a single difference between two values.
+type Difference struct {
+	// Path is the path from the compared values to where they
+	// differ, as in Animal.Name, interests[0] or tags["a"].  The
+	// keys of a map whose key type is an interface are written
+	// with their dynamic type, as in tags[int64(1)], so that keys
+	// which print alike can be told apart.  Path is empty if the
+	// compared values differ as a whole, such as two ints or
+	// values of different types.
+	Path string
+
+	Change Change
+
+	// A and B are the differing values, or nil when the path is
+	// absent from that side.
+	A, B any
+}
+
+func (d *Difference) String() string {
+	path, desc := d.describe()
+	return path + ": " + desc
+}
+
+// describe returns the path of d and a description of the change.
+func (d *Difference) describe() (path, desc string) {
+	path = d.Path
+	if path == "" {
+		path = "(value)"
+	}
+	switch d.Change {
+	case Added:
+		return path, "added " + format(d.B)
+	case Removed:
+		return path, "removed " + format(d.A)
+	}
+	if d.A != nil && d.B != nil && reflect.TypeOf(d.A) != reflect.TypeOf(d.B) {
+		return path, fmt.Sprintf("%v (%T) != %v (%T)", format(d.A), d.A, format(d.B), d.B)
+	}
+	return path, format(d.A) + " != " + format(d.B)
+}
+
+// A Report lists the differences between two values, in the order
+// of their fields, elements and sorted map keys.
+type Report []*Difference
+
+// Equal reports whether there are no differences.
+func (r Report) Equal() bool {
+	return len(r) == 0
+}
+
+// String returns the report with one difference per line, or
+// "no differences".
+func (r Report) String() string {
+	var b strings.Builder
+	r.Fprint(&b)
+	return b.String()
+}
+
+// Fprint prints the report to w as a table, with one difference
+// per line.
+func (r Report) Fprint(w io.Writer) {
+	if r.Equal() {
+		fmt.Fprintln(w, "no differences")
+		return
+	}
+	tw := tabwriter.NewWriter(w, 0, 0, 1, ' ', 0)
+	for _, d := range r {
+		path, desc := d.describe()
+		fmt.Fprintf(tw, "%v:\t%v\n", path, desc)
+	}
+	tw.Flush()
+}
+
+// Diff compares a and b and returns their differences.
+func Diff(a, b any) Report {
+	d := differ{visited: make(map[visit]bool)}
+	d.diff("", expose(reflect.ValueOf(a)), expose(reflect.ValueOf(b)))
+	return d.report
+}
+
+// A visit is a pair of references already being compared, so that
+// cycles are not followed forever.
+type visit struct {
+	a, b uintptr
+	typ  reflect.Type
+	len  int
+}
+
+type differ struct {
+	report  Report
+	visited map[visit]bool
+}
+
+func (d *differ) add(path string, change Change, a, b reflect.Value) {
+	d.report = append(d.report, &Difference{Path: path, Change: change, A: value(a), B: value(b)})
+}
+
+func (d *differ) diff(path string, a, b reflect.Value) {
+	if !a.IsValid() || !b.IsValid() {
+		if a.IsValid() != b.IsValid() {
+			d.add(path, Modified, a, b)
+		}
+		return
+	}
+	if a.Type() != b.Type() {
+		d.add(path, Modified, a, b)
+		return
+	}
+
+	if eq, ok := equalMethod(a.Type()); ok {
+		if !eq.Func.Call([]reflect.Value{a, b})[0].Bool() {
+			d.add(path, Modified, a, b)
+		}
+		return
+	}
+
+	switch a.Kind() {
+	case reflect.Pointer, reflect.Map, reflect.Slice:
+		if a.IsNil() || b.IsNil() {
+			if a.IsNil() != b.IsNil() {
+				d.add(path, Modified, a, b)
+			}
+			return
+		}
+		v := visit{a.Pointer(), b.Pointer(), a.Type(), 0}
+		if a.Kind() == reflect.Slice {
+			v.len = a.Len()
+		}
+		if d.visited[v] {
+			return
+		}
+		d.visited[v] = true
+	}
+
+	switch a.Kind() {
+	case reflect.Pointer:
+		d.diff(path, a.Elem(), b.Elem())
+
+	case reflect.Interface:
+		if a.IsNil() || b.IsNil() {
+			if a.IsNil() != b.IsNil() {
+				d.add(path, Modified, a, b)
+			}
+			return
+		}
+		d.diff(path, expose(a.Elem()), expose(b.Elem()))
+
+	case reflect.Struct:
+		for i := 0; i < a.NumField(); i++ {
+			d.diff(join(path, a.Type().Field(i).Name), field(a, i), field(b, i))
+		}
+
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < max(a.Len(), b.Len()); i++ {
+			p := fmt.Sprintf("%v[%v]", path, i)
+			switch {
+			case i >= b.Len():
+				d.add(p, Removed, a.Index(i), reflect.Value{})
+			case i >= a.Len():
+				d.add(p, Added, reflect.Value{}, b.Index(i))
+			default:
+				d.diff(p, a.Index(i), b.Index(i))
+			}
+		}
+
+	case reflect.Map:
+		for _, k := range mapKeys(a, b) {
+			p := fmt.Sprintf("%v[%v]", path, formatKey(k))
+			av, bv := a.MapIndex(k), b.MapIndex(k)
+			switch {
+			case !bv.IsValid():
+				d.add(p, Removed, av, reflect.Value{})
+			case !av.IsValid():
+				d.add(p, Added, reflect.Value{}, bv)
+			default:
+				d.diff(p, expose(av), expose(bv))
+			}
+		}
+
+	case reflect.Func:
+		if !a.IsNil() || !b.IsNil() {
+			d.add(path, Modified, a, b)
+		}
+
+	case reflect.Chan, reflect.UnsafePointer:
+		if a.Pointer() != b.Pointer() {
+			d.add(path, Modified, a, b)
+		}
+
+	default:
+		// Booleans, numbers and strings
+		if a.Interface() != b.Interface() {
+			d.add(path, Modified, a, b)
+		}
+	}
+}
+
+// equalMethod returns t's Equal method, if it has one of the form
+// func (t T) Equal(u T) bool.
+func equalMethod(t reflect.Type) (reflect.Method, bool) {
+	if t.Kind() == reflect.Interface {
+		// The dynamic values are compared instead
+		return reflect.Method{}, false
+	}
+	m, ok := t.MethodByName("Equal")
+	if !ok {
+		return m, false
+	}
+	ft := m.Type
+	ok = ft.NumIn() == 2 && ft.In(1) == t &&
+		ft.NumOut() == 1 && ft.Out(0).Kind() == reflect.Bool
+	return m, ok
+}
+
+// expose returns an addressable copy of v, so that its unexported
+// fields can be exposed by field.
+func expose(v reflect.Value) reflect.Value {
+	if !v.IsValid() || v.CanAddr() {
+		return v
+	}
+	c := reflect.New(v.Type()).Elem()
+	c.Set(v)
+	return c
+}
+
+// field returns the ith field of the addressable struct v, made
+// readable through the unsafe package if it is unexported.
+func field(v reflect.Value, i int) reflect.Value {
+	f := v.Field(i)
+	if !f.CanInterface() {
+		f = reflect.NewAt(f.Type(), unsafe.Pointer(f.UnsafeAddr())).Elem()
+	}
+	return f
+}
+
+// mapKeys returns the keys of both maps, without duplicates, in
+// the order of their formatted values, and then of their types for
+// keys of an interface type which format alike.  The keys of b are
+// looked up in a itself, so that they compare as the map's keys do.
+func mapKeys(a, b reflect.Value) []reflect.Value {
+	keys := a.MapKeys()
+	for _, k := range b.MapKeys() {
+		if !a.MapIndex(k).IsValid() {
+			keys = append(keys, k)
+		}
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		ki, kj := fmt.Sprint(keys[i]), fmt.Sprint(keys[j])
+		if ki != kj {
+			return ki < kj
+		}
+		return dynamicType(keys[i]) < dynamicType(keys[j])
+	})
+	return keys
+}
+
+// dynamicType names the type of the value v holds, which for an
+// interface is the type of its dynamic value.
+func dynamicType(v reflect.Value) string {
+	if v.Kind() == reflect.Interface && !v.IsNil() {
+		v = v.Elem()
+	}
+	return v.Type().String()
+}
+
+// formatKey formats the map key k for a path.  A key held in an
+// interface is written as a conversion to its dynamic type.
+func formatKey(k reflect.Value) string {
+	if k.Kind() == reflect.Interface && !k.IsNil() {
+		return fmt.Sprintf("%v(%v)", k.Elem().Type(), format(value(k)))
+	}
+	return format(value(k))
+}
+
+// join appends a field name to a path.
+func join(path, name string) string {
+	if path == "" {
+		return name
+	}
+	return path + "." + name
+}
+
+// value returns v as an interface value, or nil if v is invalid.
+func value(v reflect.Value) any {
+	if !v.IsValid() {
+		return nil
+	}
+	return v.Interface()
+}
+
+// format formats a differing value, quoting strings so that empty
+// and space-padded strings can be seen.
+func format(v any) string {
+	switch v := v.(type) {
+	case nil:
+		return "nil"
+	case string:
+		return fmt.Sprintf("%q", v)
+	}
+	switch rv := reflect.ValueOf(v); rv.Kind() {
+	case reflect.Pointer, reflect.Map, reflect.Slice, reflect.Func, reflect.Chan:
+		if rv.IsNil() {
+			return "nil"
+		}
+	}
+	return fmt.Sprintf("%+v", v)
+}
@@ -0,0 +1,172 @@
+package diff_test
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/whatsacomputertho/go-learn/pkg/diff"
+)
+
+type point struct{ x, y int }
+
+type tagged struct {
+	tags map[point]string
+}
+
+func TestDiffMaps(t *testing.T) {
+	tests := []struct {
+		name string
+		a, b any
+		want []string
+	}{
+		{"Equal", map[string]int{"a": 1}, map[string]int{"a": 1}, nil},
+		{"Changes",
+			map[string]int{"a": 1, "b": 2, "c": 3},
+			map[string]int{"b": 2, "c": 4, "d": 5},
+			[]string{`["a"]: removed 1`, `["c"]: 3 != 4`, `["d"]: added 5`}},
+		{"InterfaceKeys",
+			map[any]int{1: 1, "1": 1},
+			map[any]int{1: 1, int64(1): 2},
+			[]string{`[int64(1)]: added 2`, `[string("1")]: removed 1`}},
+		{"InterfaceKeyValues",
+			map[any]string{int(1): "a", nil: "n"},
+			map[any]string{int(1): "b", nil: "m"},
+			[]string{`[int(1)]: "a" != "b"`, `[nil]: "n" != "m"`}},
+		{"UnexportedField",
+			tagged{map[point]string{{1, 2}: "a", {3, 4}: "b"}},
+			tagged{map[point]string{{1, 2}: "c", {5, 6}: "d"}},
+			[]string{`tags[{x:1 y:2}]: "a" != "c"`, `tags[{x:3 y:4}]: removed "b"`, `tags[{x:5 y:6}]: added "d"`}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			for _, d := range diff.Diff(tt.a, tt.b) {
+				got = append(got, d.String())
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Diff() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+type Animal struct {
+	Name string
+	legs int
+}
+
+type Dog struct {
+	Animal
+	Tricks []string
+	Owner  *Owner
+}
+
+type Owner struct {
+	Name string
+	Dog  *Dog
+}
+
+// celsius has an Equal method, which treats temperatures within a
+// degree of each other as equal.
+type celsius struct {
+	degrees float64
+}
+
+func (c celsius) Equal(d celsius) bool {
+	return c.degrees-d.degrees < 1 && d.degrees-c.degrees < 1
+}
+
+func TestDiff(t *testing.T) {
+	rex := Dog{Animal: Animal{"Rex", 4}, Tricks: []string{"sit", "roll"}}
+	fido := Dog{Animal: Animal{"Fido", 3}, Tricks: []string{"sit", "beg", "fetch"}}
+	t0 := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name string
+		a, b any
+		want []string
+	}{
+		{"EqualStructs", rex, rex, nil},
+		{"Leaves", 1, 2, []string{"(value): 1 != 2"}},
+		{"Types", 1, int64(1), []string{"(value): 1 (int) != 1 (int64)"}},
+		{"Nil", nil, 1, []string{"(value): nil != 1"}},
+		{"EmbeddedAndUnexported", rex, fido, []string{
+			`Animal.Name: "Rex" != "Fido"`,
+			`Animal.legs: 4 != 3`,
+			`Tricks[1]: "roll" != "beg"`,
+			`Tricks[2]: added "fetch"`,
+		}},
+		{"SliceRemoved", []int{1, 2, 3}, []int{1}, []string{"[1]: removed 2", "[2]: removed 3"}},
+		{"NilAndEmptySlices", []int(nil), []int{}, []string{"(value): nil != []"}},
+		{"Arrays", [2]int{1, 2}, [2]int{1, 3}, []string{"[1]: 2 != 3"}},
+		{"PointersFollowed", &Animal{"Rex", 4}, &Animal{"Rex", 3}, []string{"legs: 4 != 3"}},
+		{"NilPointer", &Owner{Name: "Sam"}, &Owner{Name: "Sam", Dog: &Dog{}}, []string{"Dog: nil != &{Animal:{Name: legs:0} Tricks:[] Owner:<nil>}"}},
+		{"Interfaces", []any{1, "a", nil}, []any{1, "b", 2}, []string{`[1]: "a" != "b"`, "[2]: nil != 2"}},
+		{"EqualMethod", celsius{20.2}, celsius{20.7}, nil},
+		{"EqualMethodDiffers", celsius{20}, celsius{22}, []string{"(value): {degrees:20} != {degrees:22}"}},
+		// time.Time is compared with its Equal method, not by field
+		{"Time", t0, t0.In(time.FixedZone("X", 3600)), nil},
+		{"NilFuncs", (func())(nil), (func())(nil), nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			for _, d := range diff.Diff(tt.a, tt.b) {
+				got = append(got, d.String())
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Diff() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDiffFuncs(t *testing.T) {
+	// Funcs cannot be compared, so only two nil funcs are equal
+	f := func() {}
+	for _, b := range []func(){nil, f} {
+		if r := diff.Diff(f, b); len(r) != 1 || r[0].Path != "" || r[0].Change != diff.Modified {
+			t.Errorf("Diff() of a func = %v, want a single modification", r)
+		}
+	}
+}
+
+func TestDiffCycles(t *testing.T) {
+	newPair := func(name string) *Owner {
+		o := &Owner{Name: name, Dog: &Dog{Animal: Animal{Name: "Rex"}}}
+		o.Dog.Owner = o
+		return o
+	}
+	a, b := newPair("Sam"), newPair("Sam")
+	if r := diff.Diff(a, b); !r.Equal() {
+		t.Errorf("Diff() of equal cycles = %v, want no differences", r)
+	}
+
+	b = newPair("Kim")
+	want := []string{`Name: "Sam" != "Kim"`}
+	var got []string
+	for _, d := range diff.Diff(a, b) {
+		got = append(got, d.String())
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Diff() of differing cycles = %q, want %q", got, want)
+	}
+}
+
+func TestReport(t *testing.T) {
+	r := diff.Diff(map[string]int{"a": 1, "long key": 2}, map[string]int{"a": 2})
+	if r.Equal() {
+		t.Fatal("Equal() = true, want false")
+	}
+	want := "[\"a\"]:        1 != 2\n[\"long key\"]: removed 2\n"
+	if got := r.String(); got != want {
+		t.Errorf("String() =\n%v\nwant\n%v", got, want)
+	}
+	if r[1].Change != diff.Removed || r[1].A != 2 || r[1].B != nil {
+		t.Errorf("Difference = %+v, want a removal of 2", r[1])
+	}
+	if got := diff.Diff(1, 1).String(); got != "no differences\n" {
+		t.Errorf("String() = %q, want %q", got, "no differences\n")
+	}
+}
@@ -0,0 +1,235 @@
+package iox
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+)
+
+// ErrClosed is returned by writers which have already been closed.
+var ErrClosed = errors.New("iox: writer is closed")
+
+// A FlushPolicy decides when a BufferedWriterCloser passes its
+// buffered data on to the underlying writer.  After every write,
+// the policy is shown the buffered data and returns the number of
+// leading bytes to pass on in a single write, or 0 to keep them
+// buffered.  It is called repeatedly until it returns 0.
+type FlushPolicy func(buffered []byte) int
+
+// FlushChunks passes data on in chunks of exactly size bytes.
+func FlushChunks(size int) FlushPolicy {
+	if size < 1 {
+		panic(fmt.Sprintf("iox: invalid chunk size %v", size))
+	}
+	return func(buffered []byte) int {
+		if len(buffered) < size {
+			return 0
+		}
+		return size
+	}
+}
+
+// FlushLines passes data on one complete line at a time, including
+// its trailing newline.
+func FlushLines() FlushPolicy {
+	return func(buffered []byte) int {
+		return bytes.IndexByte(buffered, '\n') + 1
+	}
+}
+
+// FlushThreshold passes all buffered data on at once, as soon as
+// at least n bytes are buffered.
+func FlushThreshold(n int) FlushPolicy {
+	if n < 1 {
+		panic(fmt.Sprintf("iox: invalid flush threshold %v", n))
+	}
+	return func(buffered []byte) int {
+		if len(buffered) < n {
+			return 0
+		}
+		return len(buffered)
+	}
+}
+
+// FlushManual never passes data on by itself.  Data is only passed
+// on by Flush, Close, or a periodic flush.
+func FlushManual() FlushPolicy {
+	return func([]byte) int { return 0 }
+}
+
+// An Option configures a BufferedWriterCloser.
+type Option func(*BufferedWriterCloser)
+
+// WithPolicy sets the policy deciding when buffered data is passed
+// on.  The default is FlushChunks(8).
+func WithPolicy(policy FlushPolicy) Option {
+	return func(bwc *BufferedWriterCloser) {
+		bwc.policy = policy
+	}
+}
+
+// WithFlushInterval flushes all buffered data every interval, in
+// addition to the flushes made by the policy.  The flushing is done
+// by a goroutine which Close stops.
+func WithFlushInterval(interval time.Duration) Option {
+	if interval <= 0 {
+		panic(fmt.Sprintf("iox: invalid flush interval %v", interval))
+	}
+	return func(bwc *BufferedWriterCloser) {
+		bwc.interval = interval
+	}
+}
+
+// BufferedWriterCloser buffers the data written to it and passes it
+// on to an underlying writer as decided by its FlushPolicy, which
+// by default passes data on in 8-byte chunks.  Flush passes all
+// buffered data on immediately, and Close flushes before closing.
+// It is safe for concurrent use.
+//
+// Once a write to the underlying writer fails, the error is
+// returned by every later call to Write, Flush and Close.  Bytes
+// of the failing Write which could not be passed on are dropped
+// from the buffer, so that its count is accurate, and data
+// buffered by earlier calls is never passed on.
+type BufferedWriterCloser struct {
+	mu       sync.Mutex
+	buffer   *bytes.Buffer
+	w        io.Writer
+	err      error
+	closed   bool
+	policy   FlushPolicy
+	interval time.Duration
+	stop     chan struct{} // Closed to stop the periodic flush
+	stopped  chan struct{} // Closed once the periodic flush stops
+}
+
+// NewBufferedWriterCloser returns a BufferedWriterCloser which
+// writes to w, or to standard output if w is nil.
+func NewBufferedWriterCloser(w io.Writer, opts ...Option) *BufferedWriterCloser {
+	if w == nil {
+		w = os.Stdout
+	}
+	bwc := &BufferedWriterCloser{
+		buffer: bytes.NewBuffer([]byte{}),
+		w:      w,
+		policy: FlushChunks(8),
+	}
+	for _, opt := range opts {
+		opt(bwc)
+	}
+	if bwc.interval > 0 {
+		bwc.stop = make(chan struct{})
+		bwc.stopped = make(chan struct{})
+		go bwc.flushPeriodically()
+	}
+	return bwc
+}
+
+// Write buffers data, then passes buffered data on as the policy
+// decides.  It returns the number of bytes of data either passed on
+// or left in the buffer, which is less than len(data) only if an
+// error occurred.  Bytes of data which could not be passed on when
+// an error occurs are dropped from the buffer.
+func (bwc *BufferedWriterCloser) Write(data []byte) (int, error) {
+	bwc.mu.Lock()
+	defer bwc.mu.Unlock()
+	if bwc.closed {
+		return 0, ErrClosed
+	}
+	if bwc.err != nil {
+		return 0, bwc.err
+	}
+
+	bwc.buffer.Write(data)
+	for {
+		n := bwc.policy(bwc.buffer.Bytes())
+		if n <= 0 {
+			return len(data), nil
+		}
+		if err := bwc.flush(min(n, bwc.buffer.Len())); err != nil {
+			unwritten := min(len(data), bwc.buffer.Len())
+			bwc.buffer.Truncate(bwc.buffer.Len() - unwritten)
+			return len(data) - unwritten, err
+		}
+	}
+}
+
+// Flush passes all buffered data on to the underlying writer.
+func (bwc *BufferedWriterCloser) Flush() error {
+	bwc.mu.Lock()
+	defer bwc.mu.Unlock()
+	if bwc.closed {
+		return ErrClosed
+	}
+	if bwc.err != nil {
+		return bwc.err
+	}
+	return bwc.flush(bwc.buffer.Len())
+}
+
+// Close flushes the buffer and stops any periodic flush.  Closing
+// an already closed BufferedWriterCloser does nothing.
+func (bwc *BufferedWriterCloser) Close() error {
+	bwc.mu.Lock()
+	if bwc.closed {
+		bwc.mu.Unlock()
+		return nil
+	}
+	bwc.closed = true
+	err := bwc.err
+	if err == nil {
+		err = bwc.flush(bwc.buffer.Len())
+	}
+	bwc.mu.Unlock()
+
+	// Wait outside the lock, as the periodic flush may be waiting
+	// for it
+	if bwc.stop != nil {
+		close(bwc.stop)
+		<-bwc.stopped
+	}
+	return err
+}
+
+// flush passes the first n buffered bytes on to the underlying
+// writer in a single write.  Bytes which the underlying writer
+// accepted are removed from the buffer even if it fails.  The
+// caller must hold bwc.mu.
+func (bwc *BufferedWriterCloser) flush(n int) error {
+	if n == 0 {
+		return nil
+	}
+	data := bwc.buffer.Bytes()[:n]
+	written, err := bwc.w.Write(data)
+	if written < 0 || written > n {
+		written, err = 0, errInvalidWrite
+	}
+	if err == nil && written < n {
+		err = io.ErrShortWrite
+	}
+	bwc.buffer.Next(written)
+	bwc.err = err
+	return err
+}
+
+func (bwc *BufferedWriterCloser) flushPeriodically() {
+	defer close(bwc.stopped)
+	ticker := time.NewTicker(bwc.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			bwc.mu.Lock()
+			if !bwc.closed && bwc.err == nil {
+				bwc.flush(bwc.buffer.Len())
+			}
+			bwc.mu.Unlock()
+		case <-bwc.stop:
+			return
+		}
+	}
+}
@@ -0,0 +1,122 @@
+package iox_test
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/whatsacomputertho/go-learn/pkg/iox"
+)
+
+// chunkWriter records the data of each write made to it.  It is
+// safe for concurrent use, as a periodic flush writes from a
+// goroutine of its own.
+type chunkWriter struct {
+	mu     sync.Mutex
+	chunks []string
+}
+
+func (cw *chunkWriter) Write(p []byte) (int, error) {
+	cw.mu.Lock()
+	defer cw.mu.Unlock()
+	cw.chunks = append(cw.chunks, string(p))
+	return len(p), nil
+}
+
+func (cw *chunkWriter) Chunks() []string {
+	cw.mu.Lock()
+	defer cw.mu.Unlock()
+	return append([]string(nil), cw.chunks...)
+}
+
+func TestBufferedWriterCloserPolicies(t *testing.T) {
+	tests := []struct {
+		name   string
+		opts   []iox.Option
+		writes []string
+		want   []string // Chunks written before Flush
+	}{
+		{"Default", nil, []string{"0123456789abcdefXY"}, []string{"01234567", "89abcdef"}},
+		{"FlushChunks", []iox.Option{iox.WithPolicy(iox.FlushChunks(3))}, []string{"ab", "cdefg"}, []string{"abc", "def"}},
+		{"FlushLines", []iox.Option{iox.WithPolicy(iox.FlushLines())}, []string{"a\nb", "c\n\nd"}, []string{"a\n", "bc\n", "\n"}},
+		{"FlushThreshold", []iox.Option{iox.WithPolicy(iox.FlushThreshold(4))}, []string{"ab", "c", "defg", "h"}, []string{"abcdefg"}},
+		{"FlushManual", []iox.Option{iox.WithPolicy(iox.FlushManual())}, []string{"abcdefghijklmnop", "q\n"}, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var dst chunkWriter
+			bwc := iox.NewBufferedWriterCloser(&dst, tt.opts...)
+			var all string
+			for _, s := range tt.writes {
+				if n, err := bwc.Write([]byte(s)); n != len(s) || err != nil {
+					t.Fatalf("Write(%q) = %v, %v, want %v, <nil>", s, n, err, len(s))
+				}
+				all += s
+			}
+			if got := dst.Chunks(); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("wrote %q, want %q", got, tt.want)
+			}
+
+			// Flush passes on whatever is left in one write
+			if err := bwc.Flush(); err != nil {
+				t.Fatal(err)
+			}
+			want := tt.want
+			if rest := all[len(strings.Join(tt.want, "")):]; rest != "" {
+				want = append(append([]string(nil), want...), rest)
+			}
+			if got := dst.Chunks(); !reflect.DeepEqual(got, want) {
+				t.Errorf("after Flush wrote %q, want %q", got, want)
+			}
+		})
+	}
+}
+
+func TestBufferedWriterCloserFlushInterval(t *testing.T) {
+	var dst chunkWriter
+	bwc := iox.NewBufferedWriterCloser(&dst,
+		iox.WithPolicy(iox.FlushManual()), iox.WithFlushInterval(time.Millisecond))
+	defer bwc.Close()
+
+	for _, s := range []string{"first", "second"} {
+		bwc.Write([]byte(s))
+		deadline := time.Now().Add(5 * time.Second)
+		for chunks := dst.Chunks(); len(chunks) == 0 || chunks[len(chunks)-1] != s; chunks = dst.Chunks() {
+			if time.Now().After(deadline) {
+				t.Fatalf("%q was not flushed, wrote %q", s, chunks)
+			}
+			time.Sleep(time.Millisecond)
+		}
+	}
+}
+
+func TestBufferedWriterCloserClose(t *testing.T) {
+	var dst chunkWriter
+	bwc := iox.NewBufferedWriterCloser(&dst,
+		iox.WithPolicy(iox.FlushManual()), iox.WithFlushInterval(time.Hour))
+	bwc.Write([]byte("buffered"))
+
+	// Close flushes, and stops the periodic flush before returning
+	if err := bwc.Close(); err != nil {
+		t.Fatalf("Close() = %v, want nil", err)
+	}
+	if got, want := dst.Chunks(), []string{"buffered"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Close wrote %q, want %q", got, want)
+	}
+	if err := bwc.Close(); err != nil {
+		t.Errorf("second Close() = %v, want nil", err)
+	}
+
+	if n, err := bwc.Write([]byte("late")); n != 0 || !errors.Is(err, iox.ErrClosed) {
+		t.Errorf("Write after Close = %v, %v, want 0, %v", n, err, iox.ErrClosed)
+	}
+	if err := bwc.Flush(); !errors.Is(err, iox.ErrClosed) {
+		t.Errorf("Flush after Close = %v, want %v", err, iox.ErrClosed)
+	}
+	if got := len(dst.Chunks()); got != 1 {
+		t.Errorf("%v writes after Close, want none", got-1)
+	}
+}
@@ -0,0 +1,110 @@
+package iox
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"sync"
+)
+
+// BufferedReaderCloser mirrors BufferedWriterCloser for reading.
+// It reads from an underlying reader in chunks of a fixed size,
+// and serves reads of any size from the chunk it has buffered.
+// It is safe for concurrent use.
+//
+// Once the underlying reader returns an error, including io.EOF,
+// the error is returned after the buffered data has been read.
+type BufferedReaderCloser struct {
+	mu        sync.Mutex
+	buffer    *bytes.Buffer
+	r         io.Reader
+	chunkSize int
+	err       error
+	closed    bool
+}
+
+// NewBufferedReaderCloser returns a BufferedReaderCloser which
+// reads from r in chunks of chunkSize bytes, or 8 bytes if
+// chunkSize is 0.
+func NewBufferedReaderCloser(r io.Reader, chunkSize int) *BufferedReaderCloser {
+	if chunkSize == 0 {
+		chunkSize = 8
+	}
+	if chunkSize < 0 {
+		panic(fmt.Sprintf("iox: invalid chunk size %v", chunkSize))
+	}
+	return &BufferedReaderCloser{
+		buffer:    bytes.NewBuffer(make([]byte, 0, chunkSize)),
+		r:         r,
+		chunkSize: chunkSize,
+	}
+}
+
+// Read reads buffered data into p, first reading another chunk from
+// the underlying reader if the buffer is empty.
+func (brc *BufferedReaderCloser) Read(p []byte) (int, error) {
+	brc.mu.Lock()
+	defer brc.mu.Unlock()
+	if brc.closed {
+		return 0, ErrClosed
+	}
+	if len(p) == 0 {
+		return 0, nil
+	}
+	if brc.buffer.Len() == 0 {
+		if brc.err != nil {
+			return 0, brc.err
+		}
+		if err := brc.fill(); err != nil && brc.buffer.Len() == 0 {
+			return 0, err
+		}
+	}
+	n, _ := brc.buffer.Read(p)
+	return n, nil
+}
+
+// maxConsecutiveEmptyReads is the number of times in a row fill
+// tries the underlying reader when it returns neither data nor an
+// error, as bufio does, before reporting io.ErrNoProgress.
+const maxConsecutiveEmptyReads = 100
+
+// fill reads up to one chunk from the underlying reader into the
+// empty buffer.  The caller must hold brc.mu.  Errors from the
+// underlying reader are kept and returned by every later fill, but
+// io.ErrNoProgress is not, so that a later Read tries again.
+func (brc *BufferedReaderCloser) fill() error {
+	brc.buffer.Reset()
+	chunk := brc.buffer.AvailableBuffer()[:brc.chunkSize]
+	for i := 0; i < maxConsecutiveEmptyReads; i++ {
+		n, err := brc.r.Read(chunk)
+		if n < 0 || n > len(chunk) {
+			n, err = 0, errInvalidRead
+		}
+		if n == 0 && err == nil {
+			continue
+		}
+		brc.buffer.Write(chunk[:n])
+		brc.err = err
+		return err
+	}
+	return io.ErrNoProgress
+}
+
+// Buffered returns the number of bytes which can be read without
+// reading from the underlying reader.
+func (brc *BufferedReaderCloser) Buffered() int {
+	brc.mu.Lock()
+	defer brc.mu.Unlock()
+	return brc.buffer.Len()
+}
+
+// Close discards the buffer.  Reading after Close returns
+// ErrClosed, and closing an already closed BufferedReaderCloser
+// does nothing.  The underlying reader is not closed.
+func (brc *BufferedReaderCloser) Close() error {
+	brc.mu.Lock()
+	defer brc.mu.Unlock()
+	brc.closed = true
+	brc.buffer.Reset()
+	return nil
+}
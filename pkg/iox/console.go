@@ -0,0 +1,28 @@
+package iox
+
+import (
+	"io"
+	"os"
+)
+
+// ConsoleWriter writes data to an underlying writer unchanged.
+// The zero value writes to standard output.
+type ConsoleWriter struct {
+	w io.Writer
+}
+
+// NewConsoleWriter returns a ConsoleWriter which writes to w.
+func NewConsoleWriter(w io.Writer) ConsoleWriter {
+	return ConsoleWriter{w: w}
+}
+
+// Write writes data to the underlying writer.  It returns the
+// number of bytes of data written, which is less than len(data)
+// only if an error occurred.
+func (cw ConsoleWriter) Write(data []byte) (int, error) {
+	w := cw.w
+	if w == nil {
+		w = os.Stdout
+	}
+	return checkedWrite(w, data)
+}
@@ -0,0 +1,98 @@
+/*
+Package iox holds the Writer and Closer types from the
+interfaces lesson in a form which can be imported, along with
+the Reader types which complement them.
+
+The interfaces declared here have exactly the same method sets
+as io.Writer, io.Reader, io.Closer, io.WriteCloser and
+io.ReadCloser, so values of either can be assigned to the other,
+and every implementation in this package can be used anywhere
+the standard library expects one.  Implementations wrap an
+underlying io.Writer or io.Reader of the caller's choosing
+rather than using the console directly.
+*/
+package iox
+
+import (
+	"errors"
+	"io"
+)
+
+// Writer is the interface implemented by types which accept
+// bytes, identical to io.Writer.
+type Writer interface {
+	Write(p []byte) (n int, err error)
+}
+
+// Reader is the interface implemented by types which produce
+// bytes, identical to io.Reader.
+type Reader interface {
+	Read(p []byte) (n int, err error)
+}
+
+// Closer is the interface implemented by types which must be
+// closed once they are no longer needed, identical to io.Closer.
+type Closer interface {
+	Close() error
+}
+
+// WriterCloser composes Writer and Closer, identical to
+// io.WriteCloser.
+type WriterCloser interface {
+	Writer
+	Closer
+}
+
+// ReaderCloser composes Reader and Closer, identical to
+// io.ReadCloser.
+type ReaderCloser interface {
+	Reader
+	Closer
+}
+
+// Compile-time checks that the interfaces here and in package io
+// are interchangeable.
+var (
+	_ io.Writer      = Writer(nil)
+	_ Writer         = io.Writer(nil)
+	_ io.Reader      = Reader(nil)
+	_ Reader         = io.Reader(nil)
+	_ io.Closer      = Closer(nil)
+	_ Closer         = io.Closer(nil)
+	_ io.WriteCloser = WriterCloser(nil)
+	_ WriterCloser   = io.WriteCloser(nil)
+	_ io.ReadCloser  = ReaderCloser(nil)
+	_ ReaderCloser   = io.ReadCloser(nil)
+)
+
+// errInvalidWrite is returned when an underlying writer reports
+// writing a negative number of bytes, or more bytes than it was
+// given.
+var errInvalidWrite = errors.New("iox: invalid write result")
+
+// errInvalidRead is returned when an underlying reader reports
+// reading a negative number of bytes, or more bytes than it was
+// given room for.
+var errInvalidRead = errors.New("iox: invalid read result")
+
+// checkedWrite writes p to w in a single write, replacing an
+// invalid count with errInvalidWrite and reporting a short write
+// without an error as io.ErrShortWrite.
+func checkedWrite(w io.Writer, p []byte) (int, error) {
+	n, err := w.Write(p)
+	if n < 0 || n > len(p) {
+		return 0, errInvalidWrite
+	}
+	if err == nil && n < len(p) {
+		err = io.ErrShortWrite
+	}
+	return n, err
+}
+
+// flush flushes w if it supports flushing.
+func flush(w io.Writer) error {
+	if f, ok := w.(interface{ Flush() error }); ok {
+		return f.Flush()
+	}
+	return nil
+}
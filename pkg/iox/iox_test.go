@@ -0,0 +1,89 @@
+package iox_test
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"os"
+	"testing"
+
+	"github.com/whatsacomputertho/go-learn/pkg/iox"
+	"github.com/whatsacomputertho/go-learn/pkg/iox/ioxtest"
+)
+
+// invalidWriter claims to have written more bytes than it was
+// given.
+type invalidWriter struct{}
+
+func (invalidWriter) Write(p []byte) (int, error) {
+	return len(p) + 1, nil
+}
+
+// errAny stands in for any non-nil error in test tables.
+var errAny = errors.New("any error")
+
+func TestConsoleWriter(t *testing.T) {
+	tests := []struct {
+		name    string
+		dst     func(*bytes.Buffer) io.Writer
+		data    string
+		wantN   int
+		wantErr error // nil, io.ErrShortWrite, or errAny for any error
+		wantOut string
+	}{
+		{"writes through", func(b *bytes.Buffer) io.Writer { return b }, "Hello", 5, nil, "Hello"},
+		{"empty write", func(b *bytes.Buffer) io.Writer { return b }, "", 0, nil, ""},
+		{"short write", func(b *bytes.Buffer) io.Writer { return ioxtest.HalfWriter(b) }, "Hello", 2, io.ErrShortWrite, "He"},
+		{"failing write", func(b *bytes.Buffer) io.Writer { return ioxtest.FailingWriter(b, 2) }, "Hello", 2, ioxtest.ErrWrite, "He"},
+		{"invalid count", func(*bytes.Buffer) io.Writer { return invalidWriter{} }, "Hello", 0, errAny, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var dst bytes.Buffer
+			n, err := iox.NewConsoleWriter(tt.dst(&dst)).Write([]byte(tt.data))
+			if n != tt.wantN {
+				t.Errorf("Write(%q) n = %v, want %v", tt.data, n, tt.wantN)
+			}
+			switch {
+			case tt.wantErr == errAny:
+				if err == nil {
+					t.Errorf("Write(%q) err = <nil>, want an error", tt.data)
+				}
+			case !errors.Is(err, tt.wantErr):
+				t.Errorf("Write(%q) err = %v, want %v", tt.data, err, tt.wantErr)
+			}
+			if got := dst.String(); got != tt.wantOut {
+				t.Errorf("wrote %q, want %q", got, tt.wantOut)
+			}
+		})
+	}
+}
+
+// TestConsoleWriterStdout checks that the zero ConsoleWriter, and
+// one made with a nil writer, write to os.Stdout as it is when
+// Write is called.
+func TestConsoleWriterStdout(t *testing.T) {
+	for _, cw := range []iox.ConsoleWriter{{}, iox.NewConsoleWriter(nil)} {
+		r, w, err := os.Pipe()
+		if err != nil {
+			t.Fatal(err)
+		}
+		stdout := os.Stdout
+		os.Stdout = w
+		n, err := cw.Write([]byte("Hello, console"))
+		os.Stdout = stdout
+		w.Close()
+
+		if n != 14 || err != nil {
+			t.Errorf("Write = %v, %v, want 14, <nil>", n, err)
+		}
+		got, err := io.ReadAll(r)
+		r.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(got) != "Hello, console" {
+			t.Errorf("wrote %q to os.Stdout, want %q", got, "Hello, console")
+		}
+	}
+}
@@ -0,0 +1,265 @@
+/*
+Package ioxtest checks that writers honour the io.Writer
+contract, in the spirit of testing/iotest.
+
+The io.Writer contract requires that Write
+  - returns the number of bytes of p written, 0 <= n <= len(p)
+  - returns a non-nil error whenever n < len(p)
+  - does not modify p, even temporarily
+  - does not retain p once it returns
+
+TestWriter exercises a writer against all of these, as well as
+checking that exactly the bytes written reach the underlying
+writer, and that failures of the underlying writer are reported.
+*/
+package ioxtest
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// ErrWrite is the error returned by a FailingWriter once it has
+// written all the bytes it allows.
+var ErrWrite = errors.New("ioxtest: write failed")
+
+type failingWriter struct {
+	w    io.Writer
+	left int
+}
+
+// FailingWriter returns a writer which writes the first n bytes
+// written to it to w, and then fails with ErrWrite.  A write
+// which crosses the limit is written in part.
+func FailingWriter(w io.Writer, n int) io.Writer {
+	return &failingWriter{w, n}
+}
+
+func (fw *failingWriter) Write(p []byte) (int, error) {
+	if len(p) <= fw.left {
+		n, err := fw.w.Write(p)
+		fw.left -= n
+		return n, err
+	}
+	n, err := fw.w.Write(p[:fw.left])
+	fw.left -= n
+	if err == nil {
+		err = ErrWrite
+	}
+	return n, err
+}
+
+type halfWriter struct {
+	w io.Writer
+}
+
+// HalfWriter returns a writer which breaks the io.Writer contract
+// by writing only half of every write to w, rounded down so that
+// even a single byte write is short, and reporting no error.
+// Writers which wrap it should report the short write themselves.
+func HalfWriter(w io.Writer) io.Writer {
+	return halfWriter{w}
+}
+
+func (hw halfWriter) Write(p []byte) (int, error) {
+	return hw.w.Write(p[:len(p)/2])
+}
+
+// Finish flushes and closes w, if it supports either.  Writers
+// which buffer data must implement Flush() error or io.Closer so
+// that TestWriter can see all of the data written.
+func Finish(w io.Writer) error {
+	var err error
+	if f, ok := w.(interface{ Flush() error }); ok {
+		err = f.Flush()
+	}
+	if c, ok := w.(io.Closer); ok {
+		err = errors.Join(err, c.Close())
+	}
+	return err
+}
+
+// checkWrite checks the result of a single call to Write.
+func checkWrite(p []byte, n int, err error) error {
+	if n < 0 || n > len(p) {
+		return fmt.Errorf("Write(%q) = %v, want 0 <= n <= %v", p, n, len(p))
+	}
+	if n < len(p) && err == nil {
+		return fmt.Errorf("Write(%q) = %v, <nil>, want an error for a short write", p, n)
+	}
+	return nil
+}
+
+// writeChunks writes content to w in chunks of the given sizes,
+// cycling through them, and stops at the first error.
+func writeChunks(w io.Writer, content []byte, sizes ...int) error {
+	for i := 0; len(content) > 0; i++ {
+		size := min(sizes[i%len(sizes)], len(content))
+		p := content[:size]
+		n, err := w.Write(p)
+		if cerr := checkWrite(p, n, err); cerr != nil {
+			return cerr
+		}
+		if n != size || err != nil {
+			return fmt.Errorf("Write(%q) = %v, %v, want %v, <nil>", p, n, err, size)
+		}
+		content = content[size:]
+	}
+	return nil
+}
+
+// TestWriter checks that the writer returned by newWriter honours
+// the io.Writer contract when writing content.  newWriter is
+// called once per check, and must return a new writer which writes
+// to dst.  The first problem found by each check is returned, and
+// the problems found by every check are joined together.
+func TestWriter(newWriter func(dst io.Writer) io.Writer, content []byte) error {
+	return errors.Join(
+		testWriteAll(newWriter, content),
+		testWriteChunks(newWriter, content),
+		testWriteEmpty(newWriter),
+		testNoModify(newWriter, content),
+		testNoRetain(newWriter, content),
+		testFailing(newWriter, content),
+		testShort(newWriter, content),
+	)
+}
+
+func testWriteAll(newWriter func(io.Writer) io.Writer, content []byte) error {
+	var dst bytes.Buffer
+	w := newWriter(&dst)
+	if err := writeChunks(w, content, len(content)); err != nil {
+		return fmt.Errorf("single write: %w", err)
+	}
+	if err := Finish(w); err != nil {
+		return fmt.Errorf("single write: finish: %w", err)
+	}
+	if !bytes.Equal(dst.Bytes(), content) {
+		return fmt.Errorf("single write: wrote %q, want %q", dst.Bytes(), content)
+	}
+	return nil
+}
+
+func testWriteChunks(newWriter func(io.Writer) io.Writer, content []byte) error {
+	var dst bytes.Buffer
+	w := newWriter(&dst)
+	if err := writeChunks(w, content, 1, 2, 3, 5, 8, 13); err != nil {
+		return fmt.Errorf("chunked writes: %w", err)
+	}
+	if err := Finish(w); err != nil {
+		return fmt.Errorf("chunked writes: finish: %w", err)
+	}
+	if !bytes.Equal(dst.Bytes(), content) {
+		return fmt.Errorf("chunked writes: wrote %q, want %q", dst.Bytes(), content)
+	}
+	return nil
+}
+
+func testWriteEmpty(newWriter func(io.Writer) io.Writer) error {
+	var dst bytes.Buffer
+	w := newWriter(&dst)
+	for _, p := range [][]byte{nil, {}} {
+		if n, err := w.Write(p); n != 0 || err != nil {
+			return fmt.Errorf("empty write: Write(%q) = %v, %v, want 0, <nil>", p, n, err)
+		}
+	}
+	if err := Finish(w); err != nil {
+		return fmt.Errorf("empty write: finish: %w", err)
+	}
+	if dst.Len() != 0 {
+		return fmt.Errorf("empty write: wrote %q, want nothing", dst.Bytes())
+	}
+	return nil
+}
+
+func testNoModify(newWriter func(io.Writer) io.Writer, content []byte) error {
+	var dst bytes.Buffer
+	w := newWriter(&dst)
+	p := bytes.Clone(content)
+	if _, err := w.Write(p); err != nil {
+		return fmt.Errorf("modify: %w", err)
+	}
+	if !bytes.Equal(p, content) {
+		return fmt.Errorf("modify: Write changed its argument to %q, want %q", p, content)
+	}
+	return Finish(w)
+}
+
+func testNoRetain(newWriter func(io.Writer) io.Writer, content []byte) error {
+	var dst bytes.Buffer
+	w := newWriter(&dst)
+
+	// Scribble over every slice once Write returns.  A writer which
+	// kept hold of one would write the scribbles out later.
+	for i := range content {
+		p := []byte{content[i]}
+		if _, err := w.Write(p); err != nil {
+			return fmt.Errorf("retain: %w", err)
+		}
+		p[0] = ^p[0]
+	}
+	if err := Finish(w); err != nil {
+		return fmt.Errorf("retain: finish: %w", err)
+	}
+	if !bytes.Equal(dst.Bytes(), content) {
+		return fmt.Errorf("retain: wrote %q, want %q", dst.Bytes(), content)
+	}
+	return nil
+}
+
+func testFailing(newWriter func(io.Writer) io.Writer, content []byte) error {
+	for _, limit := range []int{0, len(content) / 2} {
+		var dst bytes.Buffer
+		w := newWriter(FailingWriter(&dst, limit))
+
+		var failed error
+		total := 0
+		for i := 0; i < len(content) && failed == nil; i += 3 {
+			p := content[i:min(i+3, len(content))]
+			n, err := w.Write(p)
+			if cerr := checkWrite(p, n, err); cerr != nil {
+				return fmt.Errorf("failing destination after %v bytes: %w", limit, cerr)
+			}
+			total += n
+			failed = err
+		}
+		if failed == nil {
+			failed = Finish(w)
+		}
+		if limit < len(content) && failed == nil {
+			return fmt.Errorf("failing destination after %v bytes: no error reported", limit)
+		}
+		if !bytes.HasPrefix(content, dst.Bytes()) || dst.Len() > total {
+			return fmt.Errorf("failing destination after %v bytes: wrote %q, want a prefix of %q no longer than the %v bytes accepted",
+				limit, dst.Bytes(), content, total)
+		}
+	}
+	return nil
+}
+
+func testShort(newWriter func(io.Writer) io.Writer, content []byte) error {
+	if len(content) == 0 {
+		return nil
+	}
+	var dst bytes.Buffer
+	w := newWriter(HalfWriter(&dst))
+
+	var failed error
+	for i := 0; i < len(content) && failed == nil; i += 3 {
+		p := content[i:min(i+3, len(content))]
+		n, err := w.Write(p)
+		if cerr := checkWrite(p, n, err); cerr != nil {
+			return fmt.Errorf("short destination: %w", cerr)
+		}
+		failed = err
+	}
+	if failed == nil {
+		failed = Finish(w)
+	}
+	if failed == nil {
+		return fmt.Errorf("short destination: wrote %q of %q without reporting an error", dst.Bytes(), content)
+	}
+	return nil
+}
@@ -0,0 +1,71 @@
+package iox
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"io"
+)
+
+// LineScanner adapts a reader into a sequence of lines, in the
+// style of bufio.Scanner.  Unlike bufio.Scanner, lines may be of
+// any length, and the number of the current line is tracked.
+// Lines are split on "\n", and a trailing "\r" is removed.  A
+// final line without a newline is still returned.
+type LineScanner struct {
+	r    *bufio.Reader
+	line []byte
+	num  int
+	err  error
+}
+
+// NewLineScanner returns a LineScanner which reads from r.
+func NewLineScanner(r io.Reader) *LineScanner {
+	return &LineScanner{r: bufio.NewReader(r)}
+}
+
+// Scan advances to the next line, which is then available through
+// Text or Bytes.  It returns false once there are no more lines,
+// either because the reader is exhausted or because it failed, in
+// which case Err returns the error.
+func (ls *LineScanner) Scan() bool {
+	if ls.err != nil {
+		return false
+	}
+	line, err := ls.r.ReadBytes('\n')
+	if err != nil {
+		ls.err = err
+		if len(line) == 0 {
+			return false
+		}
+	}
+	line = bytes.TrimSuffix(line, []byte("\n"))
+	ls.line = bytes.TrimSuffix(line, []byte("\r"))
+	ls.num++
+	return true
+}
+
+// Text returns the current line, without its line ending.
+func (ls *LineScanner) Text() string {
+	return string(ls.line)
+}
+
+// Bytes returns the current line, without its line ending.  The
+// slice may be overwritten by the next call to Scan.
+func (ls *LineScanner) Bytes() []byte {
+	return ls.line
+}
+
+// Line returns the number of the current line, starting from 1.
+func (ls *LineScanner) Line() int {
+	return ls.num
+}
+
+// Err returns the error which stopped the scanner, or nil if the
+// reader was simply exhausted.
+func (ls *LineScanner) Err() error {
+	if errors.Is(ls.err, io.EOF) {
+		return nil
+	}
+	return ls.err
+}
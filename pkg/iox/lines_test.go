@@ -0,0 +1,63 @@
+package iox_test
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"testing/iotest"
+
+	"github.com/whatsacomputertho/go-learn/pkg/iox"
+)
+
+func TestLineScanner(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    []string
+	}{
+		{"Empty", "", nil},
+		{"Newlines", "a\nb\n", []string{"a", "b"}},
+		{"NoFinalNewline", "a\nb", []string{"a", "b"}},
+		{"BlankLines", "\n\na\n", []string{"", "", "a"}},
+		{"CRLF", "a\r\nb\r\n", []string{"a", "b"}},
+		{"LoneCR", "a\rb\n", []string{"a\rb"}},
+		{"Long", strings.Repeat("x", 100000) + "\n", []string{strings.Repeat("x", 100000)}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ls := iox.NewLineScanner(iotest.HalfReader(strings.NewReader(tt.content)))
+			var got []string
+			for ls.Scan() {
+				got = append(got, ls.Text())
+				if ls.Line() != len(got) {
+					t.Errorf("Line() = %v, want %v", ls.Line(), len(got))
+				}
+			}
+			if err := ls.Err(); err != nil {
+				t.Errorf("Err() = %v, want nil", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("scanned %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLineScannerError(t *testing.T) {
+	ls := iox.NewLineScanner(iotest.TimeoutReader(strings.NewReader("a\nb\nc")))
+	var got []string
+	for ls.Scan() {
+		got = append(got, ls.Text())
+	}
+	if !errors.Is(ls.Err(), iotest.ErrTimeout) {
+		t.Errorf("Err() = %v, want %v", ls.Err(), iotest.ErrTimeout)
+	}
+	// The scanner stops at the error, and stays stopped
+	if want := []string{"a", "b", "c"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("scanned %q before the error, want %q", got, want)
+	}
+	if ls.Scan() {
+		t.Error("Scan() = true after the error")
+	}
+}
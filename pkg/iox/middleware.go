@@ -0,0 +1,262 @@
+package iox
+
+import (
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"errors"
+	"hash"
+	"io"
+	"sync/atomic"
+)
+
+// The writers in this file decorate another WriterCloser, so that
+// they can be stacked on top of one another.  Closing a decorator
+// finishes its own work first, such as writing a gzip footer, and
+// then closes the writer it decorates, so closing the outermost
+// writer of a stack closes every layer in order, from the outside
+// in.  Closing a decorator a second time does nothing, and writing
+// to it after it is closed returns ErrClosed.
+
+type nopCloser struct {
+	io.Writer
+}
+
+func (nopCloser) Close() error { return nil }
+
+// NopCloser returns a WriterCloser whose Close does nothing, for
+// decorating writers which must not be closed, such as os.Stdout.
+func NopCloser(w io.Writer) WriterCloser {
+	return nopCloser{w}
+}
+
+// PrefixWriter writes a prefix at the start of every line written
+// through it.  The prefix of a line is written when the line's
+// first byte is, so a final line without a newline is prefixed.
+type PrefixWriter struct {
+	w           WriterCloser
+	prefix      []byte
+	atLineStart bool
+	err         error
+	closed      bool
+}
+
+// NewPrefixWriter returns a PrefixWriter which writes to w.
+func NewPrefixWriter(w WriterCloser, prefix string) *PrefixWriter {
+	return &PrefixWriter{w: w, prefix: []byte(prefix), atLineStart: true}
+}
+
+// Write writes data to the underlying writer one line at a time,
+// each preceded by the prefix if it starts a line.  The count
+// returned excludes the prefixes.  Once a write to the underlying
+// writer fails, the error is returned by every later call.
+func (pw *PrefixWriter) Write(data []byte) (int, error) {
+	if pw.closed {
+		return 0, ErrClosed
+	}
+	if pw.err != nil {
+		return 0, pw.err
+	}
+	n := 0
+	for n < len(data) {
+		if pw.atLineStart && len(pw.prefix) > 0 {
+			if _, err := checkedWrite(pw.w, pw.prefix); err != nil {
+				pw.err = err
+				return n, err
+			}
+		}
+		line := data[n:]
+		if i := bytes.IndexByte(line, '\n'); i >= 0 {
+			line = line[:i+1]
+		}
+		m, err := checkedWrite(pw.w, line)
+		n += m
+		if err != nil {
+			pw.err = err
+			return n, err
+		}
+		pw.atLineStart = line[len(line)-1] == '\n'
+	}
+	return n, nil
+}
+
+// Close closes the underlying writer.
+func (pw *PrefixWriter) Close() error {
+	if pw.closed {
+		return nil
+	}
+	pw.closed = true
+	return pw.w.Close()
+}
+
+// MultiWriter duplicates every write to each of its writers in
+// turn, like io.MultiWriter.
+type MultiWriter struct {
+	ws     []WriterCloser
+	closed bool
+}
+
+// NewMultiWriter returns a MultiWriter which writes to each of ws.
+func NewMultiWriter(ws ...WriterCloser) *MultiWriter {
+	return &MultiWriter{ws: append([]WriterCloser(nil), ws...)}
+}
+
+// NewTeeWriter returns a MultiWriter which writes to w, and copies
+// everything written to the observer copy.  Closing it closes w,
+// but not copy.
+func NewTeeWriter(w WriterCloser, copy io.Writer) *MultiWriter {
+	return NewMultiWriter(w, NopCloser(copy))
+}
+
+// Write writes data to each writer in turn.  If a writer fails,
+// Write stops and returns that writer's count and error, and the
+// writers after it are not written to.
+func (mw *MultiWriter) Write(data []byte) (int, error) {
+	if mw.closed {
+		return 0, ErrClosed
+	}
+	for _, w := range mw.ws {
+		if n, err := checkedWrite(w, data); err != nil {
+			return n, err
+		}
+	}
+	return len(data), nil
+}
+
+// Close closes every writer in turn, even if some fail, and
+// returns their errors joined together.
+func (mw *MultiWriter) Close() error {
+	if mw.closed {
+		return nil
+	}
+	mw.closed = true
+	var errs []error
+	for _, w := range mw.ws {
+		errs = append(errs, w.Close())
+	}
+	return errors.Join(errs...)
+}
+
+// GzipWriter compresses the data written through it in gzip
+// format.  The compressed stream is only complete once the
+// GzipWriter is closed.
+type GzipWriter struct {
+	zw     *gzip.Writer
+	w      WriterCloser
+	closed bool
+}
+
+// NewGzipWriter returns a GzipWriter which writes the compressed
+// data to w.
+func NewGzipWriter(w WriterCloser) *GzipWriter {
+	return &GzipWriter{zw: gzip.NewWriter(w), w: w}
+}
+
+// Write compresses data.  Compressed data is buffered, so it may
+// not reach the underlying writer until Flush or Close is called.
+func (gw *GzipWriter) Write(data []byte) (int, error) {
+	if gw.closed {
+		return 0, ErrClosed
+	}
+	return gw.zw.Write(data)
+}
+
+// Flush writes any buffered compressed data to the underlying
+// writer, and flushes it too if it supports flushing.
+func (gw *GzipWriter) Flush() error {
+	if gw.closed {
+		return ErrClosed
+	}
+	if err := gw.zw.Flush(); err != nil {
+		return err
+	}
+	return flush(gw.w)
+}
+
+// Close writes the end of the gzip stream, then closes the
+// underlying writer.
+func (gw *GzipWriter) Close() error {
+	if gw.closed {
+		return nil
+	}
+	gw.closed = true
+	return errors.Join(gw.zw.Close(), gw.w.Close())
+}
+
+// ChecksumWriter computes the SHA-256 checksum of the data written
+// through it to an underlying writer.
+type ChecksumWriter struct {
+	w      WriterCloser
+	h      hash.Hash
+	closed bool
+}
+
+// NewChecksumWriter returns a ChecksumWriter which writes to w.
+func NewChecksumWriter(w WriterCloser) *ChecksumWriter {
+	return &ChecksumWriter{w: w, h: sha256.New()}
+}
+
+// Write writes data to the underlying writer, adding to the
+// checksum only the bytes it accepted.
+func (cw *ChecksumWriter) Write(data []byte) (int, error) {
+	if cw.closed {
+		return 0, ErrClosed
+	}
+	n, err := checkedWrite(cw.w, data)
+	cw.h.Write(data[:n])
+	return n, err
+}
+
+// Sum returns the SHA-256 checksum of the data written so far.
+func (cw *ChecksumWriter) Sum() []byte {
+	return cw.h.Sum(nil)
+}
+
+// Close closes the underlying writer.  The checksum is still
+// available afterwards.
+func (cw *ChecksumWriter) Close() error {
+	if cw.closed {
+		return nil
+	}
+	cw.closed = true
+	return cw.w.Close()
+}
+
+// CountingWriter counts the bytes written through it to an
+// underlying writer.  Count is safe to call while another
+// goroutine is writing.
+type CountingWriter struct {
+	w      WriterCloser
+	count  atomic.Int64
+	closed bool
+}
+
+// NewCountingWriter returns a CountingWriter which writes to w.
+func NewCountingWriter(w WriterCloser) *CountingWriter {
+	return &CountingWriter{w: w}
+}
+
+// Write writes data to the underlying writer and adds the number
+// of bytes it accepted to the count.
+func (cw *CountingWriter) Write(data []byte) (int, error) {
+	if cw.closed {
+		return 0, ErrClosed
+	}
+	n, err := checkedWrite(cw.w, data)
+	cw.count.Add(int64(n))
+	return n, err
+}
+
+// Count returns the number of bytes written so far.
+func (cw *CountingWriter) Count() int64 {
+	return cw.count.Load()
+}
+
+// Close closes the underlying writer.
+func (cw *CountingWriter) Close() error {
+	if cw.closed {
+		return nil
+	}
+	cw.closed = true
+	return cw.w.Close()
+}
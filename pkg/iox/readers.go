@@ -0,0 +1,78 @@
+package iox
+
+import (
+	"context"
+	"io"
+	"sync/atomic"
+
+	"github.com/whatsacomputertho/go-learn/pkg/limit"
+)
+
+// CountingReader counts the bytes read through it from an
+// underlying reader.  Count is safe to call while another
+// goroutine is reading.
+type CountingReader struct {
+	r     io.Reader
+	count atomic.Int64
+}
+
+// NewCountingReader returns a CountingReader which reads from r.
+func NewCountingReader(r io.Reader) *CountingReader {
+	return &CountingReader{r: r}
+}
+
+// Read reads from the underlying reader and adds the number of
+// bytes read to the count.
+func (cr *CountingReader) Read(p []byte) (int, error) {
+	n, err := cr.r.Read(p)
+	if n > 0 {
+		cr.count.Add(int64(n))
+	}
+	return n, err
+}
+
+// Count returns the number of bytes read so far.
+func (cr *CountingReader) Count() int64 {
+	return cr.count.Load()
+}
+
+// RateLimitedReader limits the rate at which bytes are read from
+// an underlying reader, taking one token from a limit.TokenBucket
+// for every byte.  A single Read returns at most the bucket's burst
+// of bytes, so reads of any size can be satisfied.
+//
+// As io.Reader has no context parameter, the context used while
+// waiting for tokens is given when constructing the reader.
+type RateLimitedReader struct {
+	ctx    context.Context
+	r      io.Reader
+	tokens *limit.TokenBucket
+}
+
+// NewRateLimitedReader returns a RateLimitedReader which reads from
+// r no faster than tokens allows.  Reads stop waiting and return
+// the context's error once ctx is done.
+func NewRateLimitedReader(ctx context.Context, r io.Reader, tokens *limit.TokenBucket) *RateLimitedReader {
+	return &RateLimitedReader{ctx: ctx, r: r, tokens: tokens}
+}
+
+// Read reads up to the bucket's burst of bytes from the underlying
+// reader, then waits for a token for each byte read before
+// returning them.  If the wait is cancelled, the bytes read are
+// still returned along with the context's error.
+func (rlr *RateLimitedReader) Read(p []byte) (int, error) {
+	if err := rlr.ctx.Err(); err != nil {
+		return 0, err
+	}
+	if len(p) > rlr.tokens.Burst() {
+		p = p[:rlr.tokens.Burst()]
+	}
+	n, err := rlr.r.Read(p)
+	if n <= 0 {
+		return n, err
+	}
+	if waitErr := rlr.tokens.WaitN(rlr.ctx, n); waitErr != nil {
+		return n, waitErr
+	}
+	return n, err
+}
@@ -0,0 +1,147 @@
+package iox_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"testing/iotest"
+	"time"
+
+	"github.com/whatsacomputertho/go-learn/pkg/iox"
+	"github.com/whatsacomputertho/go-learn/pkg/limit"
+)
+
+func TestBufferedReaderCloser(t *testing.T) {
+	content := "Hello, io.Reader contract!\nA second line"
+	for _, chunkSize := range []int{0, 1, 5, 64} {
+		brc := iox.NewBufferedReaderCloser(strings.NewReader(content), chunkSize)
+		if err := iotest.TestReader(brc, []byte(content)); err != nil {
+			t.Errorf("chunk size %v: %v", chunkSize, err)
+		}
+	}
+}
+
+// emptyReader returns neither data nor an error from its first
+// empty reads, then reads from r.
+type emptyReader struct {
+	empty int
+	r     io.Reader
+}
+
+func (er *emptyReader) Read(p []byte) (int, error) {
+	if er.empty > 0 {
+		er.empty--
+		return 0, nil
+	}
+	return er.r.Read(p)
+}
+
+func TestBufferedReaderCloserEmptyReads(t *testing.T) {
+	tests := []struct {
+		empty      int
+		noProgress bool
+	}{
+		{1, false},
+		{99, false},
+		{100, true},
+		{250, true},
+	}
+	for _, tt := range tests {
+		brc := iox.NewBufferedReaderCloser(&emptyReader{tt.empty, strings.NewReader("Hello")}, 8)
+		p := make([]byte, 8)
+
+		n, err := brc.Read(p)
+		if tt.noProgress != errors.Is(err, io.ErrNoProgress) {
+			t.Fatalf("%v empty reads: Read = %v, %v, want io.ErrNoProgress: %v", tt.empty, n, err, tt.noProgress)
+		}
+		// io.ErrNoProgress is not kept, so reading again tries
+		// the underlying reader again
+		for errors.Is(err, io.ErrNoProgress) {
+			n, err = brc.Read(p)
+		}
+		if err != nil || string(p[:n]) != "Hello" {
+			t.Errorf("%v empty reads: Read = %q, %v, want %q, <nil>", tt.empty, p[:n], err, "Hello")
+		}
+	}
+}
+
+func TestCountingReader(t *testing.T) {
+	content := "Hello, CountingReader!"
+	cr := iox.NewCountingReader(iotest.OneByteReader(strings.NewReader(content)))
+	p := make([]byte, 5)
+	for want := int64(1); want <= 3; want++ {
+		cr.Read(p)
+		if got := cr.Count(); got != want {
+			t.Fatalf("Count() = %v, want %v", got, want)
+		}
+	}
+	if _, err := io.ReadAll(cr); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := cr.Count(), int64(len(content)); got != want {
+		t.Errorf("Count() = %v, want %v", got, want)
+	}
+
+	// Bytes read alongside an error are counted too
+	cr = iox.NewCountingReader(iotest.DataErrReader(strings.NewReader(content)))
+	if _, err := io.ReadAll(cr); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := cr.Count(), int64(len(content)); got != want {
+		t.Errorf("Count() with the error alongside the data = %v, want %v", got, want)
+	}
+}
+
+func TestRateLimitedReader(t *testing.T) {
+	clock := limit.NewFakeClock(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	tokens := limit.NewTokenBucket(4, 4, clock)
+	rlr := iox.NewRateLimitedReader(context.Background(), strings.NewReader("abcdefghij"), tokens)
+	p := make([]byte, 10)
+
+	// A read takes at most the burst, which the full bucket allows
+	// straight away
+	if n, err := rlr.Read(p); string(p[:n]) != "abcd" || err != nil {
+		t.Fatalf("Read = %q, %v, want %q, <nil>", p[:n], err, "abcd")
+	}
+
+	// The next read waits for the bucket to refill
+	type result struct {
+		n   int
+		err error
+	}
+	done := make(chan result)
+	go func() {
+		n, err := rlr.Read(p)
+		done <- result{n, err}
+	}()
+	clock.BlockUntil(1)
+	clock.Advance(time.Second)
+	if r := <-done; string(p[:r.n]) != "efgh" || r.err != nil {
+		t.Fatalf("Read = %q, %v, want %q, <nil>", p[:r.n], r.err, "efgh")
+	}
+}
+
+func TestRateLimitedReaderCancel(t *testing.T) {
+	clock := limit.NewFakeClock(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	tokens := limit.NewTokenBucket(1, 2, clock)
+	tokens.AllowN(2)
+	ctx, cancel := context.WithCancel(context.Background())
+	rlr := iox.NewRateLimitedReader(ctx, strings.NewReader("abc"), tokens)
+	p := make([]byte, 10)
+
+	// Bytes read before the wait is cancelled are still returned
+	go func() {
+		clock.BlockUntil(1)
+		cancel()
+	}()
+	if n, err := rlr.Read(p); string(p[:n]) != "ab" || !errors.Is(err, context.Canceled) {
+		t.Errorf("Read = %q, %v, want %q, %v", p[:n], err, "ab", context.Canceled)
+	}
+
+	// Once cancelled, nothing more is read
+	if n, err := rlr.Read(p); n != 0 || !errors.Is(err, context.Canceled) {
+		t.Errorf("Read after cancelling = %v, %v, want 0, %v", n, err, context.Canceled)
+	}
+}
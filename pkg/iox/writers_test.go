@@ -0,0 +1,169 @@
+package iox_test
+
+import (
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"testing"
+
+	"github.com/whatsacomputertho/go-learn/pkg/iox"
+	"github.com/whatsacomputertho/go-learn/pkg/iox/ioxtest"
+)
+
+var contractContent = []byte("Hello, io.Writer contract!\nA second line\nand a third without a newline")
+
+// TestWriterContract checks every writer in the package against
+// the io.Writer contract.  Writers which change the data they
+// pass on write it through a decoder which changes it back, so
+// that ioxtest.TestWriter sees the bytes it wrote.
+func TestWriterContract(t *testing.T) {
+	tests := []struct {
+		name string
+		new  func(io.Writer) io.Writer
+	}{
+		{"ConsoleWriter", func(w io.Writer) io.Writer { return iox.NewConsoleWriter(w) }},
+		{"BufferedWriterCloser", func(w io.Writer) io.Writer { return iox.NewBufferedWriterCloser(w) }},
+		{"BufferedWriterCloser/FlushLines", func(w io.Writer) io.Writer {
+			return iox.NewBufferedWriterCloser(w, iox.WithPolicy(iox.FlushLines()))
+		}},
+		{"BufferedWriterCloser/FlushChunks(1)", func(w io.Writer) io.Writer {
+			return iox.NewBufferedWriterCloser(w, iox.WithPolicy(iox.FlushChunks(1)))
+		}},
+		{"PrefixWriter", func(w io.Writer) io.Writer {
+			return iox.NewPrefixWriter(iox.NopCloser(&unprefixWriter{w: w, prefix: []byte("> ")}), "> ")
+		}},
+		{"MultiWriter", func(w io.Writer) io.Writer { return iox.NewMultiWriter(iox.NopCloser(w)) }},
+		{"MultiWriter/two", func(w io.Writer) io.Writer {
+			return iox.NewMultiWriter(iox.NopCloser(io.Discard), iox.NopCloser(w))
+		}},
+		{"TeeWriter", func(w io.Writer) io.Writer { return iox.NewTeeWriter(iox.NopCloser(w), io.Discard) }},
+		{"GzipWriter", func(w io.Writer) io.Writer { return iox.NewGzipWriter(&gunzipWriter{w: w}) }},
+		{"ChecksumWriter", func(w io.Writer) io.Writer { return iox.NewChecksumWriter(iox.NopCloser(w)) }},
+		{"CountingWriter", func(w io.Writer) io.Writer { return iox.NewCountingWriter(iox.NopCloser(w)) }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := ioxtest.TestWriter(tt.new, contractContent); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
+
+// unprefixWriter strips the prefixes written by a PrefixWriter
+// before writing to w, and fails if a line arrives without one.
+// It relies on a PrefixWriter writing each prefix in a write of
+// its own.
+type unprefixWriter struct {
+	w       io.Writer
+	prefix  []byte
+	midLine bool
+}
+
+func (uw *unprefixWriter) Write(p []byte) (int, error) {
+	if len(p) == 0 {
+		return 0, nil
+	}
+	if !uw.midLine {
+		if !bytes.Equal(p, uw.prefix) {
+			return 0, fmt.Errorf("line %q written without the prefix %q", p, uw.prefix)
+		}
+		uw.midLine = true
+		return len(p), nil
+	}
+	n, err := uw.w.Write(p)
+	if n > 0 && p[n-1] == '\n' {
+		uw.midLine = false
+	}
+	return n, err
+}
+
+// gunzipWriter collects a gzip stream, and writes the data it
+// decompresses to w when it is closed.
+type gunzipWriter struct {
+	w          io.Writer
+	compressed bytes.Buffer
+}
+
+func (gw *gunzipWriter) Write(p []byte) (int, error) {
+	return gw.compressed.Write(p)
+}
+
+func (gw *gunzipWriter) Close() error {
+	zr, err := gzip.NewReader(&gw.compressed)
+	if err != nil {
+		return err
+	}
+	// io.Copy reports a short write as io.ErrShortWrite
+	_, err = io.Copy(gw.w, zr)
+	return errors.Join(err, zr.Close())
+}
+
+func TestPrefixWriter(t *testing.T) {
+	tests := []struct {
+		writes []string
+		want   string
+	}{
+		{[]string{"a\nb\n"}, "> a\n> b\n"},
+		{[]string{"a", "b\n", "c"}, "> ab\n> c"},
+		{[]string{"a\n", "", "\n"}, "> a\n> \n"},
+	}
+	for _, tt := range tests {
+		var dst bytes.Buffer
+		pw := iox.NewPrefixWriter(iox.NopCloser(&dst), "> ")
+		for _, s := range tt.writes {
+			if n, err := pw.Write([]byte(s)); n != len(s) || err != nil {
+				t.Fatalf("Write(%q) = %v, %v, want %v, <nil>", s, n, err, len(s))
+			}
+		}
+		if got := dst.String(); got != tt.want {
+			t.Errorf("writing %q wrote %q, want %q", tt.writes, got, tt.want)
+		}
+	}
+}
+
+func TestGzipWriter(t *testing.T) {
+	var dst bytes.Buffer
+	gw := iox.NewGzipWriter(iox.NopCloser(&dst))
+	if _, err := gw.Write(contractContent); err != nil {
+		t.Fatal(err)
+	}
+	if err := gw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := gw.Write(contractContent); !errors.Is(err, iox.ErrClosed) {
+		t.Errorf("Write after Close = %v, want %v", err, iox.ErrClosed)
+	}
+	zr, err := gzip.NewReader(&dst)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := io.ReadAll(zr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, contractContent) {
+		t.Errorf("decompressed %q, want %q", got, contractContent)
+	}
+}
+
+func TestChecksumAndCountingWriter(t *testing.T) {
+	var dst bytes.Buffer
+	cw := iox.NewCountingWriter(iox.NopCloser(ioxtest.FailingWriter(&dst, 10)))
+	sw := iox.NewChecksumWriter(cw)
+	n, err := sw.Write(contractContent)
+	if n != 10 || !errors.Is(err, ioxtest.ErrWrite) {
+		t.Fatalf("Write = %v, %v, want 10, %v", n, err, ioxtest.ErrWrite)
+	}
+	if got := cw.Count(); got != 10 {
+		t.Errorf("Count() = %v, want 10", got)
+	}
+	// Only the accepted bytes are checksummed
+	want := iox.NewChecksumWriter(iox.NopCloser(io.Discard))
+	want.Write(contractContent[:10])
+	if !bytes.Equal(sw.Sum(), want.Sum()) {
+		t.Errorf("Sum() = %x, want the checksum of the 10 bytes written, %x", sw.Sum(), want.Sum())
+	}
+}
@@ -0,0 +1,187 @@
+/*
+Package layout describes how a struct's fields are laid out in
+memory, and suggests an order for them which wastes less space.
+
+Every type has an alignment, and a value of the type must be
+stored at an address which is a multiple of it.  The compiler
+lays a struct's fields out in the order they are declared, and
+inserts padding before a field wherever the previous field ended
+at an offset which is not a multiple of its alignment.  The
+struct's own size is then padded to a multiple of its alignment,
+so that the elements of an array of them stay aligned.
+
+	type padded struct {
+		a bool  // offset 0, size 1, then 7 bytes of padding
+		b int64 // offset 8, size 8
+		c bool  // offset 16, size 1, then 7 bytes of padding
+	}
+
+Declaring fields from the most to the least strictly aligned
+never needs padding between them, so it is what Suggest does.
+
+A Layout can be read from a type compiled into the running
+program with FromReflect, whose offsets, sizes and alignments are
+those which unsafe.Offsetof, unsafe.Sizeof and unsafe.Alignof
+report, or from a type checked from source with FromTypes.
+*/
+package layout
+
+import (
+	"errors"
+	"fmt"
+	"go/types"
+	"io"
+	"reflect"
+	"sort"
+	"text/tabwriter"
+)
+
+// ErrNotStruct is returned when a layout is requested for a type
+// which is not a struct.
+var ErrNotStruct = errors.New("layout: not a struct type")
+
+// A Field is the placement of a single field within a struct.
+type Field struct {
+	Name    string
+	Type    string
+	Offset  int64
+	Size    int64
+	Align   int64
+	Padding int64 // Bytes of padding which follow the field
+}
+
+// A Layout is the placement of every field of a struct type.
+type Layout struct {
+	Type   string
+	Size   int64
+	Align  int64
+	Fields []Field
+}
+
+// Padding returns the total bytes of padding in the struct.
+func (l *Layout) Padding() int64 {
+	var padding int64
+	for _, f := range l.Fields {
+		padding += f.Padding
+	}
+	return padding
+}
+
+// FromReflect returns the layout of t, a struct type compiled into
+// the running program.
+func FromReflect(t reflect.Type) (*Layout, error) {
+	if t.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("%v: %w", t, ErrNotStruct)
+	}
+	l := &Layout{Type: t.String(), Size: int64(t.Size()), Align: int64(t.Align())}
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		l.Fields = append(l.Fields, Field{
+			Name:   f.Name,
+			Type:   f.Type.String(),
+			Offset: int64(f.Offset),
+			Size:   int64(f.Type.Size()),
+			Align:  int64(f.Type.Align()),
+		})
+	}
+	l.pad()
+	return l, nil
+}
+
+// FromTypes returns the layout of t, a type checked from source,
+// as sizes would lay it out.  types.SizesFor("gc", arch) gives
+// the layout the gc compiler uses for arch.  Type names are
+// qualified by qf.
+func FromTypes(t types.Type, sizes types.Sizes, qf types.Qualifier) (*Layout, error) {
+	st, ok := t.Underlying().(*types.Struct)
+	if !ok {
+		return nil, fmt.Errorf("%v: %w", types.TypeString(t, qf), ErrNotStruct)
+	}
+	fields := make([]*types.Var, st.NumFields())
+	for i := range fields {
+		fields[i] = st.Field(i)
+	}
+	offsets := sizes.Offsetsof(fields)
+
+	l := &Layout{Type: types.TypeString(t, qf), Size: sizes.Sizeof(t), Align: sizes.Alignof(t)}
+	for i, f := range fields {
+		l.Fields = append(l.Fields, Field{
+			Name:   f.Name(),
+			Type:   types.TypeString(f.Type(), qf),
+			Offset: offsets[i],
+			Size:   sizes.Sizeof(f.Type()),
+			Align:  sizes.Alignof(f.Type()),
+		})
+	}
+	l.pad()
+	return l, nil
+}
+
+// pad sets the padding following each field from the offset of
+// the next field, or from the struct's size for the last.
+func (l *Layout) pad() {
+	for i := range l.Fields {
+		f := &l.Fields[i]
+		next := l.Size
+		if i+1 < len(l.Fields) {
+			next = l.Fields[i+1].Offset
+		}
+		f.Padding = next - f.Offset - f.Size
+	}
+}
+
+// Suggest returns the layout of l's fields reordered from the most
+// to the least strictly aligned, which needs the least padding.
+// Fields with the same alignment keep their order.  Zero sized
+// fields come first, since the gc compiler pads a zero sized
+// final field so that a pointer to it cannot point past the
+// struct.
+func (l *Layout) Suggest() *Layout {
+	s := &Layout{Type: l.Type, Align: l.Align}
+	s.Fields = append([]Field(nil), l.Fields...)
+	sort.SliceStable(s.Fields, func(i, j int) bool {
+		a, b := s.Fields[i], s.Fields[j]
+		if (a.Size == 0) != (b.Size == 0) {
+			return a.Size == 0
+		}
+		return a.Align > b.Align
+	})
+
+	// Place the fields as the gc compiler does
+	var offset int64
+	for i := range s.Fields {
+		f := &s.Fields[i]
+		offset = roundUp(offset, f.Align)
+		f.Offset = offset
+		offset += f.Size
+	}
+	if n := len(s.Fields); n > 0 && s.Fields[n-1].Size == 0 && offset > 0 {
+		offset++
+	}
+	s.Size = roundUp(offset, s.Align)
+	s.pad()
+	return s
+}
+
+func roundUp(n, align int64) int64 {
+	if align <= 1 {
+		return n
+	}
+	return (n + align - 1) / align * align
+}
+
+// Fprint prints the layout to w as a table of fields, followed by
+// a summary.
+func (l *Layout) Fprint(w io.Writer) {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "offset\tsize\talign\tpadding\tfield\ttype")
+	for _, f := range l.Fields {
+		padding := ""
+		if f.Padding > 0 {
+			padding = fmt.Sprint(f.Padding)
+		}
+		fmt.Fprintf(tw, "%v\t%v\t%v\t%v\t%v\t%v\n", f.Offset, f.Size, f.Align, padding, f.Name, f.Type)
+	}
+	tw.Flush()
+	fmt.Fprintf(w, "%v: size %v, align %v, padding %v\n", l.Type, l.Size, l.Align, l.Padding())
+}
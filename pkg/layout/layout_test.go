@@ -0,0 +1,180 @@
+package layout_test
+
+import (
+	"errors"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"reflect"
+	"runtime"
+	"testing"
+	"unsafe"
+
+	"github.com/whatsacomputertho/go-learn/pkg/layout"
+)
+
+// src declares the same types as below, to be checked by go/types.
+const src = `package structs
+
+type padded struct {
+	a bool
+	b int64
+	c bool
+}
+
+type trailing struct {
+	a int32
+	z struct{}
+}
+
+type mixed struct {
+	s    string
+	flag bool
+	ptr  *int
+	arr  [3]int16
+	iface any
+	z    [0]int64
+	b    byte
+}
+`
+
+type padded struct {
+	a bool
+	b int64
+	c bool
+}
+
+type trailing struct {
+	a int32
+	z struct{}
+}
+
+type mixed struct {
+	s     string
+	flag  bool
+	ptr   *int
+	arr   [3]int16
+	iface any
+	z     [0]int64
+	b     byte
+}
+
+func checked(t *testing.T) *types.Package {
+	t.Helper()
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, "structs.go", src, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pkg, err := new(types.Config).Check("structs", fset, []*ast.File{f}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return pkg
+}
+
+// TestFromTypesMatchesReflect checks that the layout go/types
+// computes for the gc compiler is the one the running program has.
+func TestFromTypesMatchesReflect(t *testing.T) {
+	pkg := checked(t)
+	sizes := types.SizesFor("gc", runtime.GOARCH)
+	qf := types.RelativeTo(pkg)
+	for _, v := range []any{padded{}, trailing{}, mixed{}} {
+		rt := reflect.TypeOf(v)
+		t.Run(rt.Name(), func(t *testing.T) {
+			want, err := layout.FromReflect(rt)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, err := layout.FromTypes(pkg.Scope().Lookup(rt.Name()).Type(), sizes, qf)
+			if err != nil {
+				t.Fatal(err)
+			}
+			// Only the placements must match, as go/types and
+			// reflect spell some types differently, such as byte
+			// and uint8
+			for _, l := range []*layout.Layout{got, want} {
+				l.Type = ""
+				for i := range l.Fields {
+					l.Fields[i].Type = ""
+				}
+			}
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("FromTypes() = %+v, want %+v", got, want)
+			}
+		})
+	}
+}
+
+func TestFromReflect(t *testing.T) {
+	var p padded
+	l, err := layout.FromReflect(reflect.TypeOf(p))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []layout.Field{
+		{Name: "a", Type: "bool", Offset: int64(unsafe.Offsetof(p.a)), Size: 1, Align: 1, Padding: 7},
+		{Name: "b", Type: "int64", Offset: int64(unsafe.Offsetof(p.b)), Size: 8, Align: 8},
+		{Name: "c", Type: "bool", Offset: int64(unsafe.Offsetof(p.c)), Size: 1, Align: 1, Padding: 7},
+	}
+	if !reflect.DeepEqual(l.Fields, want) {
+		t.Errorf("Fields = %+v, want %+v", l.Fields, want)
+	}
+	if l.Size != int64(unsafe.Sizeof(p)) || l.Align != int64(unsafe.Alignof(p)) || l.Padding() != 14 {
+		t.Errorf("size %v, align %v, padding %v, want %v, %v, 14", l.Size, l.Align, l.Padding(), unsafe.Sizeof(p), unsafe.Alignof(p))
+	}
+
+	// A zero sized final field is padded, so that a pointer to it
+	// cannot point past the struct
+	var tr trailing
+	l, err = layout.FromReflect(reflect.TypeOf(tr))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if l.Size != int64(unsafe.Sizeof(tr)) || l.Size != 8 || l.Fields[1].Padding != 4 {
+		t.Errorf("trailing: size %v, final padding %v, want 8, 4", l.Size, l.Fields[1].Padding)
+	}
+
+	if _, err := layout.FromReflect(reflect.TypeOf(0)); !errors.Is(err, layout.ErrNotStruct) {
+		t.Errorf("FromReflect(int) = %v, want %v", err, layout.ErrNotStruct)
+	}
+}
+
+func TestSuggest(t *testing.T) {
+	type order struct {
+		names   []string
+		size    int64
+		padding int64
+	}
+	tests := []struct {
+		v    any
+		want order
+	}{
+		{padded{}, order{[]string{"b", "a", "c"}, 16, 6}},
+		// The zero sized field moves first, where it needs no padding
+		{trailing{}, order{[]string{"z", "a"}, 4, 0}},
+		{mixed{}, order{[]string{"z", "s", "ptr", "iface", "arr", "flag", "b"}, 48, 0}},
+	}
+	for _, tt := range tests {
+		rt := reflect.TypeOf(tt.v)
+		t.Run(rt.Name(), func(t *testing.T) {
+			l, err := layout.FromReflect(rt)
+			if err != nil {
+				t.Fatal(err)
+			}
+			s := l.Suggest()
+			var got order
+			for _, f := range s.Fields {
+				got.names = append(got.names, f.Name)
+			}
+			got.size, got.padding = s.Size, s.Padding()
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Suggest() = %+v, want %+v", got, tt.want)
+			}
+			if s.Size > l.Size {
+				t.Errorf("suggested size %v is larger than the original %v", s.Size, l.Size)
+			}
+		})
+	}
+}
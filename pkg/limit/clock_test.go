@@ -0,0 +1,86 @@
+package limit
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+var epoch = time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+func TestFakeClockTimer(t *testing.T) {
+	clock := NewFakeClock(epoch)
+	fired := clock.NewTimer(time.Second)
+	stopped := clock.NewTimer(time.Second)
+	if got := clock.Waiters(); got != 2 {
+		t.Fatalf("Waiters() = %v, want 2", got)
+	}
+
+	if !stopped.Stop() {
+		t.Error("Stop() of a pending timer = false, want true")
+	}
+	if stopped.Stop() {
+		t.Error("Stop() of a stopped timer = true, want false")
+	}
+	if got := clock.Waiters(); got != 1 {
+		t.Errorf("Waiters() after Stop() = %v, want 1", got)
+	}
+
+	clock.Advance(time.Second)
+	select {
+	case now := <-fired.C():
+		if want := epoch.Add(time.Second); !now.Equal(want) {
+			t.Errorf("timer fired at %v, want %v", now, want)
+		}
+	default:
+		t.Error("timer did not fire after Advance")
+	}
+	select {
+	case <-stopped.C():
+		t.Error("stopped timer fired")
+	default:
+	}
+	if fired.Stop() {
+		t.Error("Stop() of a fired timer = true, want false")
+	}
+}
+
+// TestCancelledWaitStopsWaiting checks that a waiter whose context
+// is cancelled no longer counts towards the clock's Waiters, so
+// that tests which advance the clock once Waiters reaches a target
+// do not count it.
+func TestCancelledWaitStopsWaiting(t *testing.T) {
+	tests := []struct {
+		name string
+		wait func(ctx context.Context, clock *FakeClock) error
+	}{
+		{"LeakyBucket", func(ctx context.Context, clock *FakeClock) error {
+			lb := NewLeakyBucket(time.Second, 2, clock)
+			lb.Allow()
+			return lb.Wait(ctx)
+		}},
+		{"TokenBucket", func(ctx context.Context, clock *FakeClock) error {
+			tb := NewTokenBucket(1, 1, clock)
+			tb.Allow()
+			return tb.Wait(ctx)
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clock := NewFakeClock(epoch)
+			ctx, cancel := context.WithCancel(context.Background())
+			done := make(chan error)
+			go func() { done <- tt.wait(ctx, clock) }()
+
+			clock.BlockUntil(1)
+			cancel()
+			if err := <-done; !errors.Is(err, context.Canceled) {
+				t.Fatalf("Wait() = %v, want %v", err, context.Canceled)
+			}
+			if got := clock.Waiters(); got != 0 {
+				t.Errorf("Waiters() after cancelling = %v, want 0", got)
+			}
+		})
+	}
+}
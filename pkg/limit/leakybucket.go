@@ -0,0 +1,106 @@
+package limit
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// LeakyBucket is a leaky bucket rate limiter.  Events are queued
+// in the bucket and leak out at a steady rate of one per interval,
+// so unlike a TokenBucket, events never happen in bursts.  The
+// bucket holds up to capacity queued events.
+type LeakyBucket struct {
+	mu       sync.Mutex
+	clock    Clock
+	interval time.Duration
+	capacity int
+	next     time.Time // When the next event may leak out
+}
+
+// NewLeakyBucket returns an empty leaky bucket which lets one event
+// through per interval and queues up to capacity events.  If clock
+// is nil, RealClock is used.
+func NewLeakyBucket(interval time.Duration, capacity int, clock Clock) *LeakyBucket {
+	if interval <= 0 || capacity < 1 {
+		panic(fmt.Sprintf("limit: invalid leaky bucket interval %v capacity %v", interval, capacity))
+	}
+	if clock == nil {
+		clock = RealClock{}
+	}
+	return &LeakyBucket{
+		clock:    clock,
+		interval: interval,
+		capacity: capacity,
+		next:     clock.Now(),
+	}
+}
+
+// Queued returns the number of events waiting to leak out.
+func (lb *LeakyBucket) Queued() int {
+	lb.mu.Lock()
+	defer lb.mu.Unlock()
+	return lb.queued(lb.clock.Now())
+}
+
+// queued returns the number of events scheduled to leak out after
+// now.  The last event scheduled leaks out one interval before
+// lb.next.  The caller must hold lb.mu.
+func (lb *LeakyBucket) queued(now time.Time) int {
+	pending := lb.next.Sub(now) - lb.interval
+	if pending <= 0 {
+		return 0
+	}
+	return int((pending + lb.interval - 1) / lb.interval)
+}
+
+// Allow reports whether an event may happen now without queueing,
+// and if so records that it did.
+func (lb *LeakyBucket) Allow() bool {
+	lb.mu.Lock()
+	defer lb.mu.Unlock()
+	now := lb.clock.Now()
+	if lb.next.After(now) {
+		return false
+	}
+	lb.next = now.Add(lb.interval)
+	return true
+}
+
+// Wait queues an event and blocks until it leaks out of the
+// bucket.  It returns ErrQueueFull without waiting if the bucket
+// is full, or ctx.Err() if ctx is done first.
+func (lb *LeakyBucket) Wait(ctx context.Context) error {
+	lb.mu.Lock()
+	now := lb.clock.Now()
+	if lb.queued(now) >= lb.capacity {
+		lb.mu.Unlock()
+		return ErrQueueFull
+	}
+	at := lb.next
+	if at.Before(now) {
+		at = now
+	}
+	lb.next = at.Add(lb.interval)
+	lb.mu.Unlock()
+
+	delay := at.Sub(now)
+	if delay <= 0 {
+		return nil
+	}
+	t := lb.clock.NewTimer(delay)
+	defer t.Stop()
+	select {
+	case <-t.C():
+		return nil
+	case <-ctx.Done():
+		// Give our slot back if nobody has queued behind us
+		lb.mu.Lock()
+		if lb.next.Equal(at.Add(lb.interval)) {
+			lb.next = at
+		}
+		lb.mu.Unlock()
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,72 @@
+package limit
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestLeakyBucketAllow(t *testing.T) {
+	type step struct {
+		advance time.Duration
+		want    bool
+	}
+	tests := []struct {
+		name  string
+		steps []step
+	}{
+		{"OnePerInterval", []step{{0, true}, {0, false}, {time.Second, true}}},
+		{"NoBursts", []step{{0, true}, {time.Hour, true}, {0, false}}},
+		{"EarlyRefused", []step{{0, true}, {999 * time.Millisecond, false}, {time.Millisecond, true}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clock := NewFakeClock(epoch)
+			lb := NewLeakyBucket(time.Second, 2, clock)
+			for i, st := range tt.steps {
+				clock.Advance(st.advance)
+				if got := lb.Allow(); got != st.want {
+					t.Errorf("step %v: Allow() = %v, want %v", i, got, st.want)
+				}
+			}
+		})
+	}
+}
+
+func TestLeakyBucketWait(t *testing.T) {
+	clock := NewFakeClock(epoch)
+	lb := NewLeakyBucket(time.Second, 2, clock)
+	if err := lb.Wait(context.Background()); err != nil {
+		t.Fatalf("Wait() on an empty bucket = %v, want nil", err)
+	}
+
+	// The next two events queue, and a third does not fit
+	done := make(chan int)
+	for i := 1; i <= 2; i++ {
+		go func(i int) {
+			if err := lb.Wait(context.Background()); err != nil {
+				t.Errorf("Wait() = %v, want nil", err)
+			}
+			done <- i
+		}(i)
+		clock.BlockUntil(i)
+	}
+	if got := lb.Queued(); got != 2 {
+		t.Errorf("Queued() = %v, want 2", got)
+	}
+	if err := lb.Wait(context.Background()); !errors.Is(err, ErrQueueFull) {
+		t.Errorf("Wait() on a full bucket = %v, want %v", err, ErrQueueFull)
+	}
+
+	// They leak out one per interval, in order
+	for want := 1; want <= 2; want++ {
+		clock.Advance(time.Second)
+		if got := <-done; got != want {
+			t.Errorf("event %v leaked out, want %v", got, want)
+		}
+		if got, want := lb.Queued(), 2-want; got != want {
+			t.Errorf("Queued() = %v, want %v", got, want)
+		}
+	}
+}
@@ -0,0 +1,41 @@
+/*
+Package limit provides concurrency and rate limiting primitives
+built on the ideas from the channels lesson.
+
+A buffered channel of struct{} already behaves like a
+semaphore: sending into it acquires one of its slots, and
+receiving from it releases one.  Semaphore builds a weighted,
+context-aware semaphore on top of exactly that.  TokenBucket
+and LeakyBucket limit how often work may happen rather than
+how much work may happen at once.
+
+Every primitive which waits accepts a context.Context so that
+callers can give up, and the rate limiters read time through a
+Clock so that they behave deterministically under a FakeClock.
+*/
+package limit
+
+import (
+	"context"
+	"errors"
+)
+
+// A Limiter decides whether an event may happen now.
+type Limiter interface {
+	// Allow reports whether an event may happen now, and if so
+	// records that it did.
+	Allow() bool
+
+	// Wait blocks until an event may happen, or until ctx is
+	// done, in which case it returns ctx.Err().
+	Wait(ctx context.Context) error
+}
+
+// ErrExceedsCapacity is returned when a request asks for more than
+// a primitive could ever grant, such as acquiring more weight than
+// a semaphore holds or more tokens than a bucket's burst.
+var ErrExceedsCapacity = errors.New("limit: request exceeds capacity")
+
+// ErrQueueFull is returned by LeakyBucket.Wait when the bucket
+// cannot queue any more events.
+var ErrQueueFull = errors.New("limit: queue is full")
@@ -0,0 +1,97 @@
+package limit
+
+import (
+	"context"
+	"fmt"
+)
+
+// Semaphore is a weighted semaphore built on a buffered channel.
+// Each unit of weight held is one value sitting in the channel's
+// buffer, so the buffer's capacity is the semaphore's size.
+type Semaphore struct {
+	slots chan struct{}
+
+	// turn serializes acquirers.  Without it, two acquirers which
+	// each hold part of the weight they need could wait on one
+	// another forever.
+	turn chan struct{}
+}
+
+// NewSemaphore returns a semaphore which holds up to size units
+// of weight at once.
+func NewSemaphore(size int) *Semaphore {
+	if size < 1 {
+		panic(fmt.Sprintf("limit: invalid semaphore size %v", size))
+	}
+	return &Semaphore{
+		slots: make(chan struct{}, size),
+		turn:  make(chan struct{}, 1),
+	}
+}
+
+// Size returns the total weight the semaphore can hold.
+func (s *Semaphore) Size() int {
+	return cap(s.slots)
+}
+
+// Held returns the weight currently held.
+func (s *Semaphore) Held() int {
+	return len(s.slots)
+}
+
+// Acquire blocks until n units of weight are held, or until ctx is
+// done, in which case nothing is held and ctx.Err() is returned.
+func (s *Semaphore) Acquire(ctx context.Context, n int) error {
+	if n > s.Size() {
+		return fmt.Errorf("%w: acquire %v of %v", ErrExceedsCapacity, n, s.Size())
+	}
+
+	select {
+	case s.turn <- struct{}{}:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	defer func() { <-s.turn }()
+
+	for i := 0; i < n; i++ {
+		select {
+		case s.slots <- struct{}{}:
+		case <-ctx.Done():
+			s.Release(i)
+			return ctx.Err()
+		}
+	}
+	return nil
+}
+
+// TryAcquire acquires n units of weight without blocking, and
+// reports whether it succeeded.
+func (s *Semaphore) TryAcquire(n int) bool {
+	select {
+	case s.turn <- struct{}{}:
+	default:
+		return false
+	}
+	defer func() { <-s.turn }()
+
+	if n > s.Size()-s.Held() {
+		return false
+	}
+	for i := 0; i < n; i++ {
+		s.slots <- struct{}{}
+	}
+	return true
+}
+
+// Release releases n units of weight.  Releasing more weight than
+// is held panics, just like receiving more values than were sent
+// would deadlock.
+func (s *Semaphore) Release(n int) {
+	for i := 0; i < n; i++ {
+		select {
+		case <-s.slots:
+		default:
+			panic("limit: semaphore released more than held")
+		}
+	}
+}
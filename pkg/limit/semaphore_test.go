@@ -0,0 +1,90 @@
+package limit
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestSemaphoreTryAcquire(t *testing.T) {
+	type step struct {
+		acquire int // Weight to acquire, or if negative, to release
+		want    bool
+		held    int
+	}
+	tests := []struct {
+		name  string
+		size  int
+		steps []step
+	}{
+		{"UpToSize", 3, []step{{1, true, 1}, {2, true, 3}, {1, false, 3}}},
+		{"AllOrNothing", 3, []step{{2, true, 2}, {2, false, 2}, {1, true, 3}}},
+		{"ReleaseMakesRoom", 2, []step{{2, true, 2}, {-1, true, 1}, {1, true, 2}}},
+		{"MoreThanSize", 2, []step{{3, false, 0}}},
+		{"Zero", 1, []step{{0, true, 0}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := NewSemaphore(tt.size)
+			for i, st := range tt.steps {
+				if st.acquire < 0 {
+					s.Release(-st.acquire)
+				} else if got := s.TryAcquire(st.acquire); got != st.want {
+					t.Errorf("step %v: TryAcquire(%v) = %v, want %v", i, st.acquire, got, st.want)
+				}
+				if got := s.Held(); got != st.held {
+					t.Errorf("step %v: Held() = %v, want %v", i, got, st.held)
+				}
+			}
+		})
+	}
+}
+
+func TestSemaphoreAcquireWaitsForRelease(t *testing.T) {
+	s := NewSemaphore(2)
+	if err := s.Acquire(context.Background(), 2); err != nil {
+		t.Fatal(err)
+	}
+	done := make(chan error)
+	go func() { done <- s.Acquire(context.Background(), 1) }()
+
+	s.Release(1)
+	if err := <-done; err != nil {
+		t.Fatalf("Acquire() = %v, want nil", err)
+	}
+	if got := s.Held(); got != 2 {
+		t.Errorf("Held() = %v, want 2", got)
+	}
+}
+
+// TestSemaphoreAcquireCancel checks that an Acquire which is
+// cancelled part way through gives back the weight it had taken.
+func TestSemaphoreAcquireCancel(t *testing.T) {
+	s := NewSemaphore(3)
+	s.TryAcquire(2)
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() { done <- s.Acquire(ctx, 2) }()
+
+	cancel()
+	if err := <-done; !errors.Is(err, context.Canceled) {
+		t.Fatalf("Acquire() = %v, want %v", err, context.Canceled)
+	}
+	if got := s.Held(); got != 2 {
+		t.Errorf("Held() after cancelling = %v, want 2", got)
+	}
+}
+
+func TestSemaphoreErrors(t *testing.T) {
+	s := NewSemaphore(2)
+	if err := s.Acquire(context.Background(), 3); !errors.Is(err, ErrExceedsCapacity) {
+		t.Errorf("Acquire(3) = %v, want %v", err, ErrExceedsCapacity)
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Error("Release() of more than is held did not panic")
+		}
+	}()
+	s.Release(1)
+}
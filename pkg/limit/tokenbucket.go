@@ -0,0 +1,114 @@
+package limit
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// TokenBucket is a token bucket rate limiter.  The bucket holds up
+// to burst tokens and is refilled at a steady rate.  Every event
+// takes a token, so events may happen in bursts of up to burst at
+// a time, but no faster than rate on average.
+type TokenBucket struct {
+	mu     sync.Mutex
+	clock  Clock
+	rate   float64 // Tokens added per second
+	burst  float64
+	tokens float64
+	last   time.Time // When tokens was last brought up to date
+}
+
+// NewTokenBucket returns a full token bucket which refills at rate
+// tokens per second, up to burst tokens.  If clock is nil,
+// RealClock is used.
+func NewTokenBucket(rate float64, burst int, clock Clock) *TokenBucket {
+	if rate <= 0 || burst < 1 {
+		panic(fmt.Sprintf("limit: invalid token bucket rate %v burst %v", rate, burst))
+	}
+	if clock == nil {
+		clock = RealClock{}
+	}
+	return &TokenBucket{
+		clock:  clock,
+		rate:   rate,
+		burst:  float64(burst),
+		tokens: float64(burst),
+		last:   clock.Now(),
+	}
+}
+
+// refill brings the token count up to date.  The caller must hold
+// tb.mu.
+func (tb *TokenBucket) refill() {
+	now := tb.clock.Now()
+	if elapsed := now.Sub(tb.last); elapsed > 0 {
+		tb.tokens = min(tb.burst, tb.tokens+elapsed.Seconds()*tb.rate)
+	}
+	tb.last = now
+}
+
+// Burst returns the number of tokens the bucket holds when full.
+func (tb *TokenBucket) Burst() int {
+	return int(tb.burst)
+}
+
+// Tokens returns the number of tokens currently in the bucket.
+func (tb *TokenBucket) Tokens() float64 {
+	tb.mu.Lock()
+	defer tb.mu.Unlock()
+	tb.refill()
+	return tb.tokens
+}
+
+func (tb *TokenBucket) Allow() bool {
+	return tb.AllowN(1)
+}
+
+// AllowN reports whether n events may happen now, and if so takes
+// n tokens.
+func (tb *TokenBucket) AllowN(n int) bool {
+	tb.mu.Lock()
+	defer tb.mu.Unlock()
+	tb.refill()
+	if tb.tokens < float64(n) {
+		return false
+	}
+	tb.tokens -= float64(n)
+	return true
+}
+
+func (tb *TokenBucket) Wait(ctx context.Context) error {
+	return tb.WaitN(ctx, 1)
+}
+
+// WaitN blocks until n tokens are available and takes them, or
+// until ctx is done.
+func (tb *TokenBucket) WaitN(ctx context.Context, n int) error {
+	if float64(n) > tb.burst {
+		return fmt.Errorf("%w: wait for %v tokens with burst %v", ErrExceedsCapacity, n, tb.burst)
+	}
+	for {
+		tb.mu.Lock()
+		tb.refill()
+		deficit := float64(n) - tb.tokens
+		if deficit <= 0 {
+			tb.tokens -= float64(n)
+			tb.mu.Unlock()
+			return nil
+		}
+		delay := time.Duration(deficit / tb.rate * float64(time.Second))
+		tb.mu.Unlock()
+
+		// Another waiter may take the tokens first, in which case
+		// we loop around and wait again
+		t := tb.clock.NewTimer(max(delay, time.Nanosecond))
+		select {
+		case <-t.C():
+		case <-ctx.Done():
+			t.Stop()
+			return ctx.Err()
+		}
+	}
+}
@@ -0,0 +1,70 @@
+package limit
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestTokenBucketAllow(t *testing.T) {
+	type step struct {
+		advance time.Duration
+		n       int
+		want    bool
+		tokens  float64 // Tokens left afterwards
+	}
+	tests := []struct {
+		name  string
+		steps []step
+	}{
+		{"Burst", []step{{0, 1, true, 2}, {0, 2, true, 0}, {0, 1, false, 0}}},
+		{"Refill", []step{{0, 3, true, 0}, {250 * time.Millisecond, 1, false, 0.5}, {250 * time.Millisecond, 1, true, 0}}},
+		{"RefillCappedAtBurst", []step{{0, 3, true, 0}, {time.Hour, 3, true, 0}, {0, 1, false, 0}}},
+		{"AllOrNothing", []step{{0, 2, true, 1}, {0, 2, false, 1}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clock := NewFakeClock(epoch)
+			tb := NewTokenBucket(2, 3, clock)
+			for i, st := range tt.steps {
+				clock.Advance(st.advance)
+				if got := tb.AllowN(st.n); got != st.want {
+					t.Errorf("step %v: AllowN(%v) = %v, want %v", i, st.n, got, st.want)
+				}
+				if got := tb.Tokens(); got != st.tokens {
+					t.Errorf("step %v: Tokens() = %v, want %v", i, got, st.tokens)
+				}
+			}
+		})
+	}
+}
+
+func TestTokenBucketWait(t *testing.T) {
+	clock := NewFakeClock(epoch)
+	tb := NewTokenBucket(2, 2, clock)
+	tb.AllowN(2)
+	done := make(chan error)
+	go func() { done <- tb.WaitN(context.Background(), 2) }()
+
+	// The two tokens take a second to refill
+	clock.BlockUntil(1)
+	clock.Advance(999 * time.Millisecond)
+	select {
+	case err := <-done:
+		t.Fatalf("WaitN() = %v before the tokens refilled", err)
+	default:
+	}
+	clock.BlockUntil(1)
+	clock.Advance(time.Millisecond)
+	if err := <-done; err != nil {
+		t.Fatalf("WaitN() = %v, want nil", err)
+	}
+	if got := tb.Tokens(); got != 0 {
+		t.Errorf("Tokens() = %v, want 0", got)
+	}
+
+	if err := tb.WaitN(context.Background(), 3); !errors.Is(err, ErrExceedsCapacity) {
+		t.Errorf("WaitN(3) = %v, want %v", err, ErrExceedsCapacity)
+	}
+}
@@ -0,0 +1,117 @@
+/*
+Package mapx provides generic map types which, unlike go's
+builtin maps, are ranged over in a predictable order.
+
+The maps-structs lesson notes that maps are not guaranteed a
+particular key order, and the looping lesson's range over the
+state populations prints them in a different order from run to
+run.  An OrderedMap ranges over its keys in the order they were
+first set, and a SortedMap ranges over them in sorted order.
+
+	populations := mapx.NewSortedMap[string, int]()
+	populations.Set("Texas", 27862596)
+	populations.Set("California", 39250017)
+	populations.Range(func(state string, population int) bool {
+		fmt.Println(state, population) // California first
+		return true
+	})
+
+Both keep a predictable order at a cost.  An OrderedMap pairs a
+builtin map with a linked list, so it does the same work as a
+builtin map plus a little bookkeeping.  A SortedMap is a
+balanced binary search tree, so its operations take O(log n)
+comparisons rather than a single hash.  Run the package's
+benchmarks, with go test -bench ., to measure the difference.
+
+Neither is safe for concurrent use, just like a builtin map.  The
+cmap package provides maps which are.
+*/
+package mapx
+
+// Map is the interface implemented by the map types in this
+// package, and by Builtin, so that they can be used
+// interchangeably.
+type Map[K comparable, V any] interface {
+	// Get returns the value stored under key, and whether the
+	// key was present.
+	Get(key K) (V, bool)
+
+	// Set stores value under key.
+	Set(key K, value V)
+
+	// Delete removes key, and reports whether it was present.
+	Delete(key K) bool
+
+	// Len returns the number of keys.
+	Len() int
+
+	// Range calls f for each key and value in the map's order,
+	// stopping early if f returns false.  Like sync.Map's
+	// Range, f must not modify the map.
+	Range(f func(key K, value V) bool)
+}
+
+var (
+	_ Map[string, int] = Builtin[string, int](nil)
+	_ Map[string, int] = (*OrderedMap[string, int])(nil)
+	_ Map[string, int] = (*SortedMap[string, int])(nil)
+)
+
+// Builtin adapts a builtin map to the Map interface, so that it
+// can be compared with the other implementations.  Its Range
+// visits keys in the builtin map's unspecified order.
+type Builtin[K comparable, V any] map[K]V
+
+// Get returns the value stored under key, and whether the key was
+// present.
+func (m Builtin[K, V]) Get(key K) (V, bool) {
+	v, ok := m[key]
+	return v, ok
+}
+
+// Set stores value under key.
+func (m Builtin[K, V]) Set(key K, value V) {
+	m[key] = value
+}
+
+// Delete removes key, and reports whether it was present.
+func (m Builtin[K, V]) Delete(key K) bool {
+	_, ok := m[key]
+	delete(m, key)
+	return ok
+}
+
+// Len returns the number of keys.
+func (m Builtin[K, V]) Len() int {
+	return len(m)
+}
+
+// Range calls f for each key and value, stopping early if f
+// returns false.
+func (m Builtin[K, V]) Range(f func(key K, value V) bool) {
+	for k, v := range m {
+		if !f(k, v) {
+			return
+		}
+	}
+}
+
+// Keys returns the keys of m in m's order.
+func Keys[K comparable, V any](m Map[K, V]) []K {
+	keys := make([]K, 0, m.Len())
+	m.Range(func(k K, _ V) bool {
+		keys = append(keys, k)
+		return true
+	})
+	return keys
+}
+
+// Values returns the values of m in m's order.
+func Values[K comparable, V any](m Map[K, V]) []V {
+	values := make([]V, 0, m.Len())
+	m.Range(func(_ K, v V) bool {
+		values = append(values, v)
+		return true
+	})
+	return values
+}
@@ -0,0 +1,134 @@
+package mapx_test
+
+import (
+	"fmt"
+	"math/rand"
+	"slices"
+	"testing"
+
+	"github.com/whatsacomputertho/go-learn/pkg/mapx"
+	"github.com/whatsacomputertho/go-learn/pkg/mapx/mapxtest"
+)
+
+// impls are the implementations of mapx.Map, and the order each
+// promises to range in.  Those made from a zero value are checked
+// but not benchmarked, as they are the same maps.
+var impls = []struct {
+	name  string
+	order mapxtest.Order
+	zero  bool
+	new   func() mapx.Map[int, int]
+}{
+	{"map", mapxtest.Unordered, false, func() mapx.Map[int, int] { return make(mapx.Builtin[int, int]) }},
+	{"OrderedMap", mapxtest.Insertion, false, func() mapx.Map[int, int] { return mapx.NewOrderedMap[int, int]() }},
+	{"OrderedMap/zero", mapxtest.Insertion, true, func() mapx.Map[int, int] { return new(mapx.OrderedMap[int, int]) }},
+	{"SortedMap", mapxtest.Sorted, false, func() mapx.Map[int, int] { return mapx.NewSortedMap[int, int]() }},
+	{"SortedMap/zero", mapxtest.Sorted, true, func() mapx.Map[int, int] { return new(mapx.SortedMap[int, int]) }},
+}
+
+func TestMap(t *testing.T) {
+	for _, impl := range impls {
+		t.Run(impl.name, func(t *testing.T) {
+			for seed := int64(1); seed <= 3; seed++ {
+				if err := mapxtest.TestMap(impl.new, impl.order, 5000, seed); err != nil {
+					t.Errorf("seed %v: %v", seed, err)
+				}
+			}
+		})
+	}
+}
+
+func TestKeysValues(t *testing.T) {
+	m := mapx.NewOrderedMap[string, int]()
+	m.Set("Ohio", 2)
+	m.Set("Texas", 1)
+	m.Set("Ohio", 4)
+	if got, want := mapx.Keys[string, int](m), []string{"Ohio", "Texas"}; !slices.Equal(got, want) {
+		t.Errorf("Keys() = %v, want %v", got, want)
+	}
+	if got, want := mapx.Values[string, int](m), []int{4, 1}; !slices.Equal(got, want) {
+		t.Errorf("Values() = %v, want %v", got, want)
+	}
+}
+
+// benchKeys are the keys of the benchmarked maps, shuffled so that
+// a SortedMap is not simply filled in ascending order.
+var benchKeys = rand.New(rand.NewSource(1)).Perm(10000)
+
+// fill returns a new map holding every key.
+func fill(newMap func() mapx.Map[int, int], keys []int) mapx.Map[int, int] {
+	m := newMap()
+	for _, k := range keys {
+		m.Set(k, k)
+	}
+	return m
+}
+
+// benchmark runs bench for each implementation.
+func benchmark(b *testing.B, bench func(b *testing.B, newMap func() mapx.Map[int, int])) {
+	for _, impl := range impls {
+		if impl.zero {
+			continue
+		}
+		b.Run(fmt.Sprintf("%v/n=%v", impl.name, len(benchKeys)), func(b *testing.B) {
+			bench(b, impl.new)
+		})
+	}
+}
+
+// BenchmarkSet measures setting new keys, starting a new map each
+// time every key has been set.
+func BenchmarkSet(b *testing.B) {
+	benchmark(b, func(b *testing.B, newMap func() mapx.Map[int, int]) {
+		var m mapx.Map[int, int]
+		for i := 0; i < b.N; i++ {
+			if i%len(benchKeys) == 0 {
+				m = newMap()
+			}
+			m.Set(benchKeys[i%len(benchKeys)], i)
+		}
+	})
+}
+
+// BenchmarkGet measures getting keys which are present.
+func BenchmarkGet(b *testing.B) {
+	benchmark(b, func(b *testing.B, newMap func() mapx.Map[int, int]) {
+		m := fill(newMap, benchKeys)
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			m.Get(benchKeys[i%len(benchKeys)])
+		}
+	})
+}
+
+// BenchmarkDelete measures deleting keys which are present,
+// refilling the map each time every key has been deleted.
+func BenchmarkDelete(b *testing.B) {
+	benchmark(b, func(b *testing.B, newMap func() mapx.Map[int, int]) {
+		var m mapx.Map[int, int]
+		for i := 0; i < b.N; i++ {
+			if i%len(benchKeys) == 0 {
+				b.StopTimer()
+				m = fill(newMap, benchKeys)
+				b.StartTimer()
+			}
+			m.Delete(benchKeys[i%len(benchKeys)])
+		}
+	})
+}
+
+// BenchmarkRange measures ranging over a full map, one operation
+// per key visited.
+func BenchmarkRange(b *testing.B) {
+	benchmark(b, func(b *testing.B, newMap func() mapx.Map[int, int]) {
+		m := fill(newMap, benchKeys)
+		b.ResetTimer()
+		visited := 0
+		for visited < b.N {
+			m.Range(func(int, int) bool {
+				visited++
+				return visited < b.N
+			})
+		}
+	})
+}
@@ -0,0 +1,151 @@
+/*
+Package mapxtest holds the check of mapx.Map implementations
+shared by the tests of the mapx and cmap packages.
+*/
+package mapxtest
+
+import (
+	"errors"
+	"fmt"
+	"math/rand"
+	"slices"
+
+	"github.com/whatsacomputertho/go-learn/pkg/mapx"
+)
+
+// An Order is the order in which a map promises to range over its
+// keys.
+type Order int
+
+const (
+	// Unordered maps, like builtin maps, range in any order.
+	Unordered Order = iota
+
+	// Insertion ordered maps range in the order in which keys
+	// were first set since they were last deleted.
+	Insertion
+
+	// Sorted maps range in ascending key order.
+	Sorted
+)
+
+func (o Order) String() string {
+	switch o {
+	case Unordered:
+		return "unordered"
+	case Insertion:
+		return "insertion order"
+	case Sorted:
+		return "sorted"
+	}
+	return fmt.Sprintf("Order(%d)", int(o))
+}
+
+// TestMap performs ops random operations on an empty map returned
+// by newMap, drawing keys from a range of about ops/2 so that keys
+// are often set, read and deleted more than once.  After each
+// operation the map's results are compared with a builtin map's,
+// and its Range is checked against order every so often.  The
+// sequence of operations is determined by seed.
+func TestMap(newMap func() mapx.Map[int, int], order Order, ops int, seed int64) error {
+	m := newMap()
+	want := make(map[int]int)
+	var inserted []int // Keys in insertion order
+	rng := rand.New(rand.NewSource(seed))
+	keys := max(ops/2, 1)
+
+	var errs []error
+	check := func(op string, got, want any) bool {
+		if got != want {
+			errs = append(errs, fmt.Errorf("%v = %v, want %v", op, got, want))
+		}
+		return got == want
+	}
+
+	for i := 0; i < ops && len(errs) == 0; i++ {
+		k := rng.Intn(keys)
+		switch rng.Intn(3) {
+		case 0:
+			v := rng.Int()
+			if _, ok := want[k]; !ok {
+				inserted = append(inserted, k)
+			}
+			m.Set(k, v)
+			want[k] = v
+		case 1:
+			_, present := want[k]
+			if present {
+				inserted = slices.Delete(inserted, slices.Index(inserted, k), slices.Index(inserted, k)+1)
+			}
+			check(fmt.Sprintf("Delete(%v)", k), m.Delete(k), present)
+			delete(want, k)
+		case 2:
+			v, ok := m.Get(k)
+			wantV, wantOK := want[k]
+			if check(fmt.Sprintf("Get(%v) ok", k), ok, wantOK) {
+				check(fmt.Sprintf("Get(%v)", k), v, wantV)
+			}
+		}
+		check("Len()", m.Len(), len(want))
+		if i%100 == 0 || i == ops-1 {
+			if err := checkRange(m, want, inserted, order); err != nil {
+				errs = append(errs, fmt.Errorf("after %v operations: %w", i+1, err))
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// checkRange checks that m ranges over exactly the entries of
+// want, in order, and that Range stops when asked.
+func checkRange(m mapx.Map[int, int], want map[int]int, inserted []int, order Order) error {
+	var got []int
+	m.Range(func(k, v int) bool {
+		got = append(got, k)
+		if v != want[k] {
+			got = nil
+			return false
+		}
+		return true
+	})
+	if got == nil && len(want) > 0 {
+		return errors.New("Range yielded a key with the wrong value")
+	}
+
+	var wantKeys []int
+	switch order {
+	case Insertion:
+		wantKeys = inserted
+	case Sorted, Unordered:
+		wantKeys = make([]int, 0, len(want))
+		for k := range want {
+			wantKeys = append(wantKeys, k)
+		}
+		slices.Sort(wantKeys)
+		if order == Unordered {
+			got = slices.Clone(got)
+			slices.Sort(got)
+		}
+	}
+	if !slices.Equal(got, wantKeys) {
+		return fmt.Errorf("Range yielded keys %v, want %v in %v", truncate(got), truncate(wantKeys), order)
+	}
+
+	calls := 0
+	m.Range(func(int, int) bool {
+		calls++
+		return false
+	})
+	if len(want) > 0 && calls != 1 {
+		return fmt.Errorf("Range called f %v times after it returned false, want 1", calls)
+	}
+	return nil
+}
+
+// truncate shortens long lists of keys in error messages.
+func truncate(keys []int) string {
+	if len(keys) > 10 {
+		return fmt.Sprintf("%v...", keys[:10])
+	}
+	return fmt.Sprint(keys)
+}
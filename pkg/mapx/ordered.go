@@ -0,0 +1,114 @@
+package mapx
+
+// OrderedMap is a map which ranges over its keys in the order in
+// which they were first set.  Setting a key which is already
+// present updates its value without moving it, while deleting a
+// key and setting it again moves it to the end.
+//
+// The zero value is an empty map ready to use.  An OrderedMap
+// must not be copied after first use, as its list points into it.
+type OrderedMap[K comparable, V any] struct {
+	entries map[K]*orderedEntry[K, V]
+
+	// root is the sentinel of a circular doubly linked list of
+	// the entries in insertion order, so that an entry can be
+	// unlinked in constant time without special cases for the
+	// first and last entries.
+	root orderedEntry[K, V]
+}
+
+type orderedEntry[K comparable, V any] struct {
+	key        K
+	value      V
+	prev, next *orderedEntry[K, V]
+}
+
+// NewOrderedMap returns an empty OrderedMap.
+func NewOrderedMap[K comparable, V any]() *OrderedMap[K, V] {
+	return new(OrderedMap[K, V])
+}
+
+// Get returns the value stored under key, and whether the key was
+// present.
+func (m *OrderedMap[K, V]) Get(key K) (V, bool) {
+	if e, ok := m.entries[key]; ok {
+		return e.value, true
+	}
+	var zero V
+	return zero, false
+}
+
+// Set stores value under key.  A new key is placed after every
+// key already in the map.
+func (m *OrderedMap[K, V]) Set(key K, value V) {
+	if e, ok := m.entries[key]; ok {
+		e.value = value
+		return
+	}
+	if m.entries == nil {
+		m.entries = make(map[K]*orderedEntry[K, V])
+		m.root.prev, m.root.next = &m.root, &m.root
+	}
+	e := &orderedEntry[K, V]{key: key, value: value, prev: m.root.prev, next: &m.root}
+	e.prev.next = e
+	m.root.prev = e
+	m.entries[key] = e
+}
+
+// Delete removes key, and reports whether it was present.
+func (m *OrderedMap[K, V]) Delete(key K) bool {
+	e, ok := m.entries[key]
+	if !ok {
+		return false
+	}
+	e.prev.next = e.next
+	e.next.prev = e.prev
+	// Release the neighbours for the garbage collector
+	e.prev, e.next = nil, nil
+	delete(m.entries, key)
+	return true
+}
+
+// Len returns the number of keys.
+func (m *OrderedMap[K, V]) Len() int {
+	return len(m.entries)
+}
+
+// Range calls f for each key and value in insertion order,
+// stopping early if f returns false.
+func (m *OrderedMap[K, V]) Range(f func(key K, value V) bool) {
+	if m.entries == nil {
+		return
+	}
+	for e := m.root.next; e != &m.root; e = e.next {
+		if !f(e.key, e.value) {
+			return
+		}
+	}
+}
+
+// Oldest returns the first key set in m and its value, or false
+// if m is empty.
+func (m *OrderedMap[K, V]) Oldest() (K, V, bool) {
+	if m.Len() == 0 {
+		var (
+			key   K
+			value V
+		)
+		return key, value, false
+	}
+	return m.root.next.key, m.root.next.value, true
+}
+
+// Newest returns the last key set in m and its value, or false if
+// m is empty.
+func (m *OrderedMap[K, V]) Newest() (K, V, bool) {
+	if m.Len() == 0 {
+		var (
+			key   K
+			value V
+		)
+		return key, value, false
+	}
+	return m.root.prev.key, m.root.prev.value, true
+}
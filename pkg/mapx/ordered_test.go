@@ -0,0 +1,59 @@
+package mapx_test
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/whatsacomputertho/go-learn/pkg/mapx"
+)
+
+func TestOrderedMapOrder(t *testing.T) {
+	tests := []struct {
+		name string
+		ops  func(m *mapx.OrderedMap[string, int])
+		want []string
+	}{
+		{"Empty", func(m *mapx.OrderedMap[string, int]) {}, nil},
+		{"Insertion", func(m *mapx.OrderedMap[string, int]) {
+			m.Set("Texas", 1)
+			m.Set("Ohio", 2)
+			m.Set("Alaska", 3)
+		}, []string{"Texas", "Ohio", "Alaska"}},
+		{"UpdateStays", func(m *mapx.OrderedMap[string, int]) {
+			m.Set("Ohio", 2)
+			m.Set("Texas", 1)
+			m.Set("Ohio", 4)
+		}, []string{"Ohio", "Texas"}},
+		{"ReinsertMoves", func(m *mapx.OrderedMap[string, int]) {
+			m.Set("Ohio", 2)
+			m.Set("Texas", 1)
+			m.Delete("Ohio")
+			m.Set("Ohio", 4)
+		}, []string{"Texas", "Ohio"}},
+		{"DeleteAll", func(m *mapx.OrderedMap[string, int]) {
+			m.Set("Ohio", 2)
+			m.Delete("Ohio")
+		}, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := new(mapx.OrderedMap[string, int])
+			tt.ops(m)
+			if got := mapx.Keys[string, int](m); !slices.Equal(got, tt.want) {
+				t.Errorf("Keys() = %v, want %v", got, tt.want)
+			}
+
+			oldest, _, ok := m.Oldest()
+			newest, _, _ := m.Newest()
+			if len(tt.want) == 0 {
+				if ok {
+					t.Errorf("Oldest() = %v, true, want false", oldest)
+				}
+				return
+			}
+			if oldest != tt.want[0] || newest != tt.want[len(tt.want)-1] {
+				t.Errorf("Oldest(), Newest() = %v, %v, want %v, %v", oldest, newest, tt.want[0], tt.want[len(tt.want)-1])
+			}
+		})
+	}
+}
@@ -0,0 +1,224 @@
+package mapx
+
+import "cmp"
+
+// SortedMap is a map which ranges over its keys in ascending
+// order.  It is an AVL tree: a binary search tree which rebalances
+// itself as keys are set and deleted, so that the heights of any
+// node's two subtrees differ by at most one, and every operation
+// takes O(log n) comparisons.
+//
+// The zero value is an empty map ready to use.
+type SortedMap[K cmp.Ordered, V any] struct {
+	root *sortedNode[K, V]
+	len  int
+}
+
+type sortedNode[K cmp.Ordered, V any] struct {
+	key         K
+	value       V
+	left, right *sortedNode[K, V]
+	height      int
+}
+
+// NewSortedMap returns an empty SortedMap.
+func NewSortedMap[K cmp.Ordered, V any]() *SortedMap[K, V] {
+	return new(SortedMap[K, V])
+}
+
+// Get returns the value stored under key, and whether the key was
+// present.
+func (m *SortedMap[K, V]) Get(key K) (V, bool) {
+	n := m.root
+	for n != nil {
+		switch c := cmp.Compare(key, n.key); {
+		case c < 0:
+			n = n.left
+		case c > 0:
+			n = n.right
+		default:
+			return n.value, true
+		}
+	}
+	var zero V
+	return zero, false
+}
+
+// Set stores value under key.
+func (m *SortedMap[K, V]) Set(key K, value V) {
+	var added bool
+	m.root, added = m.insert(m.root, key, value)
+	if added {
+		m.len++
+	}
+}
+
+func (m *SortedMap[K, V]) insert(n *sortedNode[K, V], key K, value V) (*sortedNode[K, V], bool) {
+	if n == nil {
+		return &sortedNode[K, V]{key: key, value: value, height: 1}, true
+	}
+	var added bool
+	switch c := cmp.Compare(key, n.key); {
+	case c < 0:
+		n.left, added = m.insert(n.left, key, value)
+	case c > 0:
+		n.right, added = m.insert(n.right, key, value)
+	default:
+		n.value = value
+		return n, false
+	}
+	return rebalance(n), added
+}
+
+// Delete removes key, and reports whether it was present.
+func (m *SortedMap[K, V]) Delete(key K) bool {
+	var deleted bool
+	m.root, deleted = m.remove(m.root, key)
+	if deleted {
+		m.len--
+	}
+	return deleted
+}
+
+func (m *SortedMap[K, V]) remove(n *sortedNode[K, V], key K) (*sortedNode[K, V], bool) {
+	if n == nil {
+		return nil, false
+	}
+	var deleted bool
+	switch c := cmp.Compare(key, n.key); {
+	case c < 0:
+		n.left, deleted = m.remove(n.left, key)
+	case c > 0:
+		n.right, deleted = m.remove(n.right, key)
+	default:
+		if n.left == nil {
+			return n.right, true
+		}
+		if n.right == nil {
+			return n.left, true
+		}
+		// Replace the node with its successor, the smallest key
+		// in its right subtree
+		succ := n.right
+		for succ.left != nil {
+			succ = succ.left
+		}
+		n.key, n.value = succ.key, succ.value
+		n.right, _ = m.remove(n.right, succ.key)
+		deleted = true
+	}
+	return rebalance(n), deleted
+}
+
+// Len returns the number of keys.
+func (m *SortedMap[K, V]) Len() int {
+	return m.len
+}
+
+// Range calls f for each key and value in ascending key order,
+// stopping early if f returns false.
+func (m *SortedMap[K, V]) Range(f func(key K, value V) bool) {
+	// Walk the tree in order with an explicit stack of the
+	// nodes whose left subtree is being visited
+	var stack []*sortedNode[K, V]
+	n := m.root
+	for n != nil || len(stack) > 0 {
+		for n != nil {
+			stack = append(stack, n)
+			n = n.left
+		}
+		n = stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		if !f(n.key, n.value) {
+			return
+		}
+		n = n.right
+	}
+}
+
+// Min returns the smallest key in m and its value, or false if m
+// is empty.
+func (m *SortedMap[K, V]) Min() (K, V, bool) {
+	n := m.root
+	for n != nil && n.left != nil {
+		n = n.left
+	}
+	return entry(n)
+}
+
+// Max returns the largest key in m and its value, or false if m
+// is empty.
+func (m *SortedMap[K, V]) Max() (K, V, bool) {
+	n := m.root
+	for n != nil && n.right != nil {
+		n = n.right
+	}
+	return entry(n)
+}
+
+// Height returns the height of m's tree, which is at most about
+// 1.44 log2(n+2) for an AVL tree holding n keys.
+func (m *SortedMap[K, V]) Height() int {
+	return height(m.root)
+}
+
+func entry[K cmp.Ordered, V any](n *sortedNode[K, V]) (K, V, bool) {
+	if n == nil {
+		var (
+			key   K
+			value V
+		)
+		return key, value, false
+	}
+	return n.key, n.value, true
+}
+
+func height[K cmp.Ordered, V any](n *sortedNode[K, V]) int {
+	if n == nil {
+		return 0
+	}
+	return n.height
+}
+
+// rebalance restores the AVL property at n, whose subtrees are
+// balanced but may differ in height by two, and returns the root
+// of the rebalanced subtree.
+func rebalance[K cmp.Ordered, V any](n *sortedNode[K, V]) *sortedNode[K, V] {
+	fix(n)
+	switch balance := height(n.left) - height(n.right); {
+	case balance > 1:
+		if height(n.left.left) < height(n.left.right) {
+			n.left = rotateLeft(n.left)
+		}
+		return rotateRight(n)
+	case balance < -1:
+		if height(n.right.right) < height(n.right.left) {
+			n.right = rotateRight(n.right)
+		}
+		return rotateLeft(n)
+	}
+	return n
+}
+
+// rotateLeft lifts n's right child above n.
+func rotateLeft[K cmp.Ordered, V any](n *sortedNode[K, V]) *sortedNode[K, V] {
+	r := n.right
+	n.right, r.left = r.left, n
+	fix(n)
+	fix(r)
+	return r
+}
+
+// rotateRight lifts n's left child above n.
+func rotateRight[K cmp.Ordered, V any](n *sortedNode[K, V]) *sortedNode[K, V] {
+	l := n.left
+	n.left, l.right = l.right, n
+	fix(n)
+	fix(l)
+	return l
+}
+
+// fix recomputes n's height from its children's.
+func fix[K cmp.Ordered, V any](n *sortedNode[K, V]) {
+	n.height = 1 + max(height(n.left), height(n.right))
+}
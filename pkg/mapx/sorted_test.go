@@ -0,0 +1,54 @@
+package mapx_test
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+
+	"github.com/whatsacomputertho/go-learn/pkg/mapx"
+)
+
+func TestSortedMapBalanced(t *testing.T) {
+	ascending := make([]int, 1000)
+	for i := range ascending {
+		ascending[i] = i
+	}
+	tests := []struct {
+		name string
+		keys []int
+	}{
+		// Setting keys in order unbalances a plain binary tree
+		{"Ascending", ascending},
+		{"Shuffled", rand.New(rand.NewSource(1)).Perm(1000)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := mapx.NewSortedMap[int, int]()
+			for _, k := range tt.keys {
+				m.Set(k, k)
+			}
+			// Deleting every other key must rebalance the tree too
+			for k := 0; k < len(tt.keys); k += 2 {
+				m.Delete(k)
+			}
+			n := m.Len()
+			if limit := int(1.44*math.Log2(float64(n+2))) + 1; m.Height() > limit {
+				t.Errorf("Height() = %v with %v keys, want at most %v", m.Height(), n, limit)
+			}
+			if k, _, _ := m.Min(); k != 1 {
+				t.Errorf("Min() = %v, want 1", k)
+			}
+			if k, _, _ := m.Max(); k != len(tt.keys)-1 {
+				t.Errorf("Max() = %v, want %v", k, len(tt.keys)-1)
+			}
+		})
+	}
+
+	var m mapx.SortedMap[int, int]
+	if k, _, ok := m.Min(); ok {
+		t.Errorf("Min() of an empty map = %v, true, want false", k)
+	}
+	if k, _, ok := m.Max(); ok {
+		t.Errorf("Max() of an empty map = %v, true, want false", k)
+	}
+}
@@ -0,0 +1,292 @@
+/*
+Package methodset explains which interfaces a type satisfies, and
+why it fails to satisfy the others.
+
+The method set of a named type T holds the methods declared with
+a value receiver, while the method set of *T also holds those
+declared with a pointer receiver.  Methods of embedded fields are
+promoted into both, following the same rule.  A type satisfies
+an interface when every method of the interface is in its method
+set, which is why the interfaces lesson can assign a
+*BufferedWriterCloser to a WriterCloser, but not a
+BufferedWriterCloser.
+
+This package works on type-checked code from go/types, so it
+sees the same method sets the compiler does.
+*/
+package methodset
+
+import (
+	"fmt"
+	"go/types"
+	"io"
+	"sort"
+	"strings"
+	"text/tabwriter"
+)
+
+// A Method is one method in a method set.
+type Method struct {
+	Name      string
+	Signature string // The signature, without the func keyword
+	Pointer   bool   // Declared with a pointer receiver
+	Promoted  string // The embedded field it is promoted through, if any
+}
+
+// Satisfaction records whether T and *T satisfy an interface, and
+// why not when they do not.
+type Satisfaction struct {
+	Interface      string
+	Value          bool
+	Pointer        bool
+	ValueMissing   []string // Why T does not satisfy the interface
+	PointerMissing []string // Why *T does not satisfy the interface
+	Related        bool     // *T has a method or field named like one of the interface's methods
+}
+
+// A Report describes the method sets of T and *T, and the
+// interfaces they satisfy.
+type Report struct {
+	Type       string
+	Value      []Method // The method set of T
+	Pointer    []Method // The method set of *T
+	Interfaces []Satisfaction
+}
+
+// Analyze reports the method sets of the named type T and *T, and
+// checks them against each of ifaces.  Types declared in the
+// package from are printed unqualified, and others are qualified
+// by their package's name.  Unexported methods declared outside
+// from are left out, as code in from cannot call them.
+func Analyze(named *types.Named, ifaces []*types.TypeName, from *types.Package) Report {
+	qf := func(p *types.Package) string {
+		if p == from {
+			return ""
+		}
+		return p.Name()
+	}
+	ptr := types.NewPointer(named)
+	r := Report{
+		Type:    types.TypeString(named, qf),
+		Value:   methods(named, from, qf),
+		Pointer: methods(ptr, from, qf),
+	}
+	for _, tn := range ifaces {
+		iface, ok := tn.Type().Underlying().(*types.Interface)
+		if !ok {
+			continue
+		}
+		s := Satisfaction{
+			Interface:      types.TypeString(tn.Type(), qf),
+			ValueMissing:   Explain(named, iface, qf),
+			PointerMissing: Explain(ptr, iface, qf),
+		}
+		s.Value = len(s.ValueMissing) == 0
+		s.Pointer = len(s.PointerMissing) == 0
+		for i := 0; i < iface.NumMethods() && !s.Related; i++ {
+			m := iface.Method(i)
+			obj, index, _ := types.LookupFieldOrMethod(ptr, false, m.Pkg(), m.Name())
+			s.Related = obj != nil || index != nil
+		}
+		r.Interfaces = append(r.Interfaces, s)
+	}
+	return r
+}
+
+// methods lists the method set of t which is visible from the
+// package from, sorted by name.
+func methods(t types.Type, from *types.Package, qf types.Qualifier) []Method {
+	mset := types.NewMethodSet(t)
+	list := make([]Method, 0, mset.Len())
+	for i := 0; i < mset.Len(); i++ {
+		sel := mset.At(i)
+		fn := sel.Obj().(*types.Func)
+		if !fn.Exported() && fn.Pkg() != from {
+			continue
+		}
+		sig := fn.Type().(*types.Signature)
+		m := Method{
+			Name:      fn.Name(),
+			Signature: strings.TrimPrefix(types.TypeString(sig, qf), "func"),
+		}
+		if recv := sig.Recv(); recv != nil {
+			_, m.Pointer = recv.Type().(*types.Pointer)
+		}
+		if path := sel.Index(); len(path) > 1 {
+			m.Promoted = embeddedPath(t, path[:len(path)-1])
+		}
+		list = append(list, m)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Name < list[j].Name })
+	return list
+}
+
+// embeddedPath names the chain of embedded fields selected by the
+// field indices path, starting from t, as in "Animal" or
+// "Bird.Animal".
+func embeddedPath(t types.Type, path []int) string {
+	var names []string
+	for _, i := range path {
+		if p, ok := t.Underlying().(*types.Pointer); ok {
+			t = p.Elem()
+		}
+		st, ok := t.Underlying().(*types.Struct)
+		if !ok {
+			break
+		}
+		f := st.Field(i)
+		names = append(names, f.Name())
+		t = f.Type()
+	}
+	return strings.Join(names, ".")
+}
+
+// Explain returns the reasons t does not satisfy iface, one per
+// method of iface which t lacks, or nil if t satisfies it.
+func Explain(t types.Type, iface *types.Interface, qf types.Qualifier) []string {
+	if types.Implements(t, iface) {
+		return nil
+	}
+	if !iface.IsMethodSet() {
+		return []string{"the interface is a constraint, and is not satisfied by " + types.TypeString(t, qf)}
+	}
+
+	var reasons []string
+	mset := types.NewMethodSet(t)
+	for i := 0; i < iface.NumMethods(); i++ {
+		want := iface.Method(i)
+		wantSig := strings.TrimPrefix(types.TypeString(want.Type(), qf), "func")
+		if sel := mset.Lookup(want.Pkg(), want.Name()); sel != nil {
+			have := sel.Obj().(*types.Func)
+			if !types.Identical(have.Type(), want.Type()) {
+				haveSig := strings.TrimPrefix(types.TypeString(have.Type(), qf), "func")
+				reasons = append(reasons, fmt.Sprintf("method %v has the wrong signature: have %v%v, want %v%v",
+					want.Name(), want.Name(), haveSig, want.Name(), wantSig))
+			}
+			continue
+		}
+
+		// Not in the method set, but the selector may still find
+		// something by that name
+		obj, index, _ := types.LookupFieldOrMethod(t, true, want.Pkg(), want.Name())
+		switch obj := obj.(type) {
+		case *types.Func:
+			// Only in the method set of the pointer type
+			reasons = append(reasons, fmt.Sprintf("method %v has a pointer receiver, so it is only in the method set of *%v",
+				want.Name(), types.TypeString(deref(t), qf)))
+		case *types.Var:
+			reasons = append(reasons, fmt.Sprintf("%v is a field, not a method", obj.Name()))
+		default:
+			if index != nil {
+				reasons = append(reasons, fmt.Sprintf("method %v is ambiguous, as it is promoted from more than one embedded field at the same depth",
+					want.Name()))
+			} else {
+				reasons = append(reasons, fmt.Sprintf("missing method %v%v", want.Name(), wantSig))
+			}
+		}
+	}
+	return reasons
+}
+
+func deref(t types.Type) types.Type {
+	if p, ok := t.(*types.Pointer); ok {
+		return p.Elem()
+	}
+	return t
+}
+
+// Interfaces returns the interface types declared at the top level
+// of pkg, and if imports is set, those exported by the packages it
+// imports directly.  The predeclared error interface is always
+// included, and empty interfaces, which every type satisfies, are
+// left out.
+func Interfaces(pkg *types.Package, imports bool) []*types.TypeName {
+	var list []*types.TypeName
+	add := func(scope *types.Scope, exportedOnly bool) {
+		for _, name := range scope.Names() {
+			tn, ok := scope.Lookup(name).(*types.TypeName)
+			if !ok || (exportedOnly && !tn.Exported()) {
+				continue
+			}
+			iface, ok := tn.Type().Underlying().(*types.Interface)
+			if !ok || iface.Empty() {
+				continue
+			}
+			if named, ok := tn.Type().(*types.Named); ok && named.TypeParams().Len() > 0 {
+				// Generic interfaces must be instantiated first
+				continue
+			}
+			list = append(list, tn)
+		}
+	}
+	add(pkg.Scope(), false)
+	if imports {
+		imps := append([]*types.Package(nil), pkg.Imports()...)
+		sort.Slice(imps, func(i, j int) bool { return imps[i].Path() < imps[j].Path() })
+		for _, imp := range imps {
+			add(imp.Scope(), true)
+		}
+	}
+	list = append(list, types.Universe.Lookup("error").(*types.TypeName))
+	return list
+}
+
+// Fprint writes the report to w.
+func (r Report) Fprint(w io.Writer) {
+	printMethods := func(title string, list []Method) {
+		fmt.Fprintf(w, "%v (%v method(s))\n", title, len(list))
+		for _, m := range list {
+			fmt.Fprintf(w, "  %v%v", m.Name, m.Signature)
+			var notes []string
+			if m.Pointer {
+				notes = append(notes, "pointer receiver")
+			}
+			if m.Promoted != "" {
+				notes = append(notes, "promoted from "+m.Promoted)
+			}
+			if len(notes) > 0 {
+				fmt.Fprintf(w, "  [%v]", strings.Join(notes, ", "))
+			}
+			fmt.Fprintln(w)
+		}
+	}
+	printMethods("method set of "+r.Type, r.Value)
+	printMethods("method set of *"+r.Type, r.Pointer)
+
+	if len(r.Interfaces) == 0 {
+		return
+	}
+	fmt.Fprintln(w)
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(tw, "interface\t%v\t*%v\t\n", r.Type, r.Type)
+	for _, s := range r.Interfaces {
+		fmt.Fprintf(tw, "%v\t%v\t%v\t\n", s.Interface, yesNo(s.Value), yesNo(s.Pointer))
+	}
+	tw.Flush()
+
+	for _, s := range r.Interfaces {
+		// Explaining every method of every unrelated interface
+		// is noise, so only near misses are explained
+		if s.Value || !s.Related {
+			continue
+		}
+		fmt.Fprintln(w)
+		fmt.Fprintf(w, "%v does not satisfy %v:\n", r.Type, s.Interface)
+		for _, reason := range s.ValueMissing {
+			fmt.Fprintf(w, "  %v\n", reason)
+		}
+		if !s.Pointer {
+			fmt.Fprintf(w, "*%v does not satisfy %v:\n", r.Type, s.Interface)
+			for _, reason := range s.PointerMissing {
+				fmt.Fprintf(w, "  %v\n", reason)
+			}
+		}
+	}
+}
+
+func yesNo(b bool) string {
+	if b {
+		return "yes"
+	}
+	return "no"
+}
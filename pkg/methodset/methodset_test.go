@@ -0,0 +1,217 @@
+package methodset_test
+
+import (
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/whatsacomputertho/go-learn/pkg/methodset"
+)
+
+const animalsSrc = `package animals
+
+type Animal struct{ Name string }
+
+func (a Animal) Speak() string   { return a.Name }
+func (a *Animal) Rename(n string) { a.Name = n }
+func (a Animal) secret() int     { return 0 }
+
+type Dog struct {
+	Animal
+	Breed string
+}
+
+func (d *Dog) Fetch() {}
+
+type Speaker interface{ Speak() string }
+`
+
+const mainSrc = `package main
+
+import "example.com/animals"
+
+type Renamer interface{ Rename(string) }
+
+type Secretive interface{ secret() int }
+
+type Walker interface{ Walk() }
+
+type Breeder interface{ Breed() string }
+
+type Puppy struct {
+	*animals.Dog
+}
+
+func (p Puppy) hidden() {}
+
+func main() {}
+`
+
+// packages is a types.Importer for packages already checked.
+type packages map[string]*types.Package
+
+func (p packages) Import(path string) (*types.Package, error) {
+	if pkg, ok := p[path]; ok {
+		return pkg, nil
+	}
+	return nil, fmt.Errorf("package %q not found", path)
+}
+
+// check type-checks the animals and main packages.
+func check(t *testing.T) (animals, main *types.Package) {
+	t.Helper()
+	fset := token.NewFileSet()
+	pkgs := packages{}
+	for _, src := range []struct{ path, src string }{
+		{"example.com/animals", animalsSrc},
+		{"main", mainSrc},
+	} {
+		f, err := parser.ParseFile(fset, src.path+".go", src.src, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		conf := types.Config{Importer: pkgs}
+		pkg, err := conf.Check(src.path, fset, []*ast.File{f}, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		pkgs[src.path] = pkg
+	}
+	return pkgs["example.com/animals"], pkgs["main"]
+}
+
+func lookup(t *testing.T, pkg *types.Package, name string) *types.Named {
+	t.Helper()
+	named, ok := pkg.Scope().Lookup(name).Type().(*types.Named)
+	if !ok {
+		t.Fatalf("%v is not a named type", name)
+	}
+	return named
+}
+
+func names(list []methodset.Method) []string {
+	var out []string
+	for _, m := range list {
+		out = append(out, m.Name)
+	}
+	return out
+}
+
+func TestAnalyzeMethodSets(t *testing.T) {
+	animals, main := check(t)
+	tests := []struct {
+		typ          string
+		pkg          *types.Package
+		from         *types.Package
+		value, point []string
+	}{
+		// Pointer receiver methods are only in the method set of *T
+		{"Animal", animals, animals, []string{"Speak", "secret"}, []string{"Rename", "Speak", "secret"}},
+		// Unexported methods are hidden outside their package
+		{"Animal", animals, main, []string{"Speak"}, []string{"Rename", "Speak"}},
+		// Methods promoted through a value field follow the same rule
+		{"Dog", animals, main, []string{"Speak"}, []string{"Fetch", "Rename", "Speak"}},
+		// Through a pointer field, every method is promoted into both
+		{"Puppy", main, main, []string{"Fetch", "Rename", "Speak", "hidden"}, []string{"Fetch", "Rename", "Speak", "hidden"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.typ+"/from "+tt.from.Name(), func(t *testing.T) {
+			r := methodset.Analyze(lookup(t, tt.pkg, tt.typ), nil, tt.from)
+			if got := names(r.Value); !reflect.DeepEqual(got, tt.value) {
+				t.Errorf("method set of %v = %v, want %v", tt.typ, got, tt.value)
+			}
+			if got := names(r.Pointer); !reflect.DeepEqual(got, tt.point) {
+				t.Errorf("method set of *%v = %v, want %v", tt.typ, got, tt.point)
+			}
+		})
+	}
+}
+
+func TestAnalyzeMethodDetails(t *testing.T) {
+	animals, main := check(t)
+	r := methodset.Analyze(lookup(t, main, "Puppy"), nil, main)
+	want := []methodset.Method{
+		{Name: "Fetch", Signature: "()", Pointer: true, Promoted: "Dog"},
+		{Name: "Rename", Signature: "(n string)", Pointer: true, Promoted: "Dog.Animal"},
+		{Name: "Speak", Signature: "() string", Promoted: "Dog.Animal"},
+		{Name: "hidden", Signature: "()"},
+	}
+	if !reflect.DeepEqual(r.Value, want) {
+		t.Errorf("method set of Puppy = %+v, want %+v", r.Value, want)
+	}
+	// Types from other packages are qualified
+	if got, want := methodset.Analyze(lookup(t, animals, "Dog"), nil, main).Type, "animals.Dog"; got != want {
+		t.Errorf("Type = %q, want %q", got, want)
+	}
+}
+
+func TestAnalyzeInterfaces(t *testing.T) {
+	_, main := check(t)
+	dog := lookup(t, main, "Puppy").Underlying().(*types.Struct).Field(0).Type().(*types.Pointer).Elem().(*types.Named)
+	tests := []struct {
+		iface          string
+		value, pointer bool
+		related        bool
+		valueMissing   string // A reason given for Dog, if any
+	}{
+		{"Renamer", false, true, true, "method Rename has a pointer receiver, so it is only in the method set of *animals.Dog"},
+		{"Secretive", false, false, false, "missing method secret() int"},
+		{"Walker", false, false, false, "missing method Walk()"},
+		{"Breeder", false, false, true, "Breed is a field, not a method"},
+		{"error", false, false, false, "missing method Error() string"},
+	}
+	ifaces := methodset.Interfaces(main, true)
+	r := methodset.Analyze(dog, ifaces, main)
+	got := make(map[string]methodset.Satisfaction)
+	for _, s := range r.Interfaces {
+		got[s.Interface] = s
+	}
+	if _, ok := got["animals.Speaker"]; !ok {
+		t.Errorf("interfaces of imported packages not checked, got %v", got)
+	}
+	for _, tt := range tests {
+		s, ok := got[tt.iface]
+		if !ok {
+			t.Errorf("%v not checked", tt.iface)
+			continue
+		}
+		if s.Value != tt.value || s.Pointer != tt.pointer || s.Related != tt.related {
+			t.Errorf("%v: Value, Pointer, Related = %v, %v, %v, want %v, %v, %v",
+				tt.iface, s.Value, s.Pointer, s.Related, tt.value, tt.pointer, tt.related)
+		}
+		if reasons := strings.Join(s.ValueMissing, "; "); reasons != tt.valueMissing {
+			t.Errorf("%v: Dog does not satisfy it as %q, want %q", tt.iface, reasons, tt.valueMissing)
+		}
+	}
+}
+
+func TestExplainAmbiguous(t *testing.T) {
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, "amb.go", `package amb
+
+type A struct{}
+func (A) Move() {}
+type B struct{}
+func (B) Move() {}
+type AB struct{ A; B }
+type Mover interface{ Move() }
+`, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pkg, err := new(types.Config).Check("amb", fset, []*ast.File{f}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	mover := pkg.Scope().Lookup("Mover").Type().Underlying().(*types.Interface)
+	got := methodset.Explain(pkg.Scope().Lookup("AB").Type(), mover, nil)
+	want := []string{"method Move is ambiguous, as it is promoted from more than one embedded field at the same depth"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Explain() = %q, want %q", got, want)
+	}
+}
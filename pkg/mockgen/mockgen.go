@@ -0,0 +1,369 @@
+/*
+Package mockgen generates recording mocks of interfaces.
+
+Code which depends on an interface rather than a concrete type
+can be tested by substituting a mock for the real
+implementation.  For an interface Writer, the generated
+WriterMock records every call made to it, along with its
+arguments, and returns whatever the test programs it to.
+
+	m := &WriterMock{}
+	m.WriteReturns(0, errors.New("disk full"))
+	err := save(m)
+	m.WriteCallCount()     // 1
+	m.WriteCalls()[0].Arg0 // The bytes save wrote
+
+Each method M of the interface gets
+  - an exported MFunc field which, when set, is called to produce
+    the method's results
+  - an MReturns method which programs fixed results
+  - MCalls and MCallCount methods reporting the recorded calls
+
+Each call's arguments are recorded in fields named after the
+parameters, so a parameter p is recorded in field P.  Unnamed
+parameters are named by their position, so the first is recorded
+in field Arg0.  A method which is called without being programmed
+returns the zero values of its results.  Byte slice arguments are copied
+when recorded, as io.Writer implementations must not retain
+them.  Mocks are safe for concurrent use.
+*/
+package mockgen
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"go/types"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// Generate returns the source of a file declaring mocks of the
+// named interfaces of pkg, in a package named pkgName.  If pkgName
+// is the name of pkg, the file is meant to be placed in pkg itself.
+// The command line which generated the file is recorded in its
+// header.
+func Generate(pkg *types.Package, names []string, pkgName, command string) ([]byte, error) {
+	g := &generator{
+		from:    pkg,
+		same:    pkgName == pkg.Name(),
+		imports: make(map[string]string),
+	}
+	g.imports["sync"] = "sync"
+	if !g.same && pkg.Name() == "main" {
+		return nil, fmt.Errorf("mocks of package main must be generated into package main, as it cannot be imported")
+	}
+
+	var body bytes.Buffer
+	for _, name := range names {
+		obj := pkg.Scope().Lookup(name)
+		if obj == nil {
+			return nil, fmt.Errorf("no interface %v in package %v", name, pkg.Path())
+		}
+		tn, ok := obj.(*types.TypeName)
+		if !ok {
+			return nil, fmt.Errorf("%v is not a type", name)
+		}
+		iface, ok := tn.Type().Underlying().(*types.Interface)
+		if !ok {
+			return nil, fmt.Errorf("%v is not an interface", name)
+		}
+		if named, ok := tn.Type().(*types.Named); ok && named.TypeParams().Len() > 0 {
+			return nil, fmt.Errorf("%v is generic, which is not supported", name)
+		}
+		if !iface.IsMethodSet() {
+			return nil, fmt.Errorf("%v is a constraint, not an ordinary interface", name)
+		}
+		if err := g.mock(&body, tn, iface); err != nil {
+			return nil, err
+		}
+	}
+
+	var out bytes.Buffer
+	fmt.Fprintf(&out, "// Code generated by %v. DO NOT EDIT.\n\n", command)
+	fmt.Fprintf(&out, "package %v\n\n", pkgName)
+	paths := make([]string, 0, len(g.imports))
+	for path := range g.imports {
+		paths = append(paths, path)
+	}
+	// Standard library packages first, then the rest, as
+	// goimports groups them
+	sort.Slice(paths, func(i, j int) bool {
+		if si, sj := isStd(paths[i]), isStd(paths[j]); si != sj {
+			return si
+		}
+		return paths[i] < paths[j]
+	})
+	out.WriteString("import (\n")
+	for i, path := range paths {
+		if i > 0 && isStd(path) != isStd(paths[i-1]) {
+			out.WriteString("\n")
+		}
+		name := g.imports[path]
+		if name == lastElem(path) {
+			fmt.Fprintf(&out, "\t%q\n", path)
+		} else {
+			fmt.Fprintf(&out, "\t%v %q\n", name, path)
+		}
+	}
+	out.WriteString(")\n")
+	out.Write(body.Bytes())
+
+	src, err := format.Source(out.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("formatting generated code: %w\n%s", err, out.Bytes())
+	}
+	return src, nil
+}
+
+// generator holds the state of a single call to Generate.
+type generator struct {
+	from    *types.Package
+	same    bool              // Generating into from itself
+	imports map[string]string // Import path to package name
+}
+
+// qualifier names the packages of the types in the generated code,
+// importing them as needed.
+func (g *generator) qualifier(p *types.Package) string {
+	if g.same && p == g.from {
+		return ""
+	}
+	if name, ok := g.imports[p.Path()]; ok {
+		return name
+	}
+	// Give packages which share a name distinct names
+	name := p.Name()
+	for i := 2; g.nameTaken(name); i++ {
+		name = p.Name() + strconv.Itoa(i)
+	}
+	g.imports[p.Path()] = name
+	return name
+}
+
+func (g *generator) nameTaken(name string) bool {
+	for _, n := range g.imports {
+		if n == name {
+			return true
+		}
+	}
+	return false
+}
+
+// param is a parameter or result of a mocked method.
+type param struct {
+	name     string // Name in the generated method
+	field    string // Name of the field recording it
+	typ      string
+	variadic bool
+	bytes    bool // A []byte, copied when recorded
+}
+
+func (g *generator) mock(w *bytes.Buffer, tn *types.TypeName, iface *types.Interface) error {
+	ifaceName := types.TypeString(tn.Type(), g.qualifier)
+	mock := tn.Name() + "Mock"
+
+	fmt.Fprintf(w, "\n// %v is a recording mock of %v.\n", mock, ifaceName)
+	fmt.Fprintf(w, "type %v struct {\n\tmu sync.Mutex\n", mock)
+	type method struct {
+		name            string
+		params, results []param
+	}
+	var methods []method
+	for i := 0; i < iface.NumMethods(); i++ {
+		fn := iface.Method(i)
+		if !fn.Exported() && !g.same {
+			return fmt.Errorf("%v has unexported method %v, so it can only be mocked in its own package", tn.Name(), fn.Name())
+		}
+		sig := fn.Type().(*types.Signature)
+		// Parameters and results share a scope in the mocked
+		// method, so their names must not clash
+		used := map[string]bool{"m": true, "fn": true}
+		m := method{name: fn.Name()}
+		m.params = g.params(sig.Params(), sig.Variadic(), "arg", used)
+		m.results = g.params(sig.Results(), false, "r", used)
+		methods = append(methods, m)
+		if len(m.results) > 0 {
+			fmt.Fprintf(w, "\t// %vFunc, if set, is called by %v to produce its results\n", m.name, m.name)
+		} else {
+			fmt.Fprintf(w, "\t// %vFunc, if set, is called by %v\n", m.name, m.name)
+		}
+		fmt.Fprintf(w, "\t%vFunc func(%v) %v\n", m.name, paramList(m.params, false), resultList(m.results, false))
+	}
+	// The calls are kept apart from the mock's own fields and
+	// methods, which an unexported method's could clash with
+	w.WriteString("\tcalls struct {\n")
+	for _, m := range methods {
+		fmt.Fprintf(w, "\t\t%v []%v%vCall\n", m.name, mock, m.name)
+	}
+	w.WriteString("\t}\n}\n")
+	fmt.Fprintf(w, "\nvar _ %v = (*%v)(nil)\n", ifaceName, mock)
+
+	for _, m := range methods {
+		call := mock + m.name + "Call"
+		calls := "calls." + m.name
+
+		fmt.Fprintf(w, "\n// %v records the arguments of a call to %v.%v.\n", call, mock, m.name)
+		if len(m.params) == 0 {
+			fmt.Fprintf(w, "type %v struct{}\n", call)
+		} else {
+			fmt.Fprintf(w, "type %v struct {\n", call)
+			for _, p := range m.params {
+				typ := p.typ
+				if p.variadic {
+					typ = "[]" + typ
+				}
+				fmt.Fprintf(w, "\t%v %v\n", p.field, typ)
+			}
+			w.WriteString("}\n")
+		}
+
+		// The method itself
+		if len(m.results) > 0 {
+			fmt.Fprintf(w, "\n// %v records the call, then returns the results of %vFunc, if set.\n", m.name, m.name)
+		} else {
+			fmt.Fprintf(w, "\n// %v records the call, then calls %vFunc, if set.\n", m.name, m.name)
+		}
+		fmt.Fprintf(w, "func (m *%v) %v(%v) %v {\n", mock, m.name, paramList(m.params, true), resultList(m.results, true))
+		fmt.Fprintf(w, "\tm.mu.Lock()\n\tm.%v = append(m.%v, %v{", calls, calls, call)
+		for i, p := range m.params {
+			if i > 0 {
+				w.WriteString(", ")
+			}
+			if p.bytes {
+				fmt.Fprintf(w, "%v: append([]byte(nil), %v...)", p.field, p.name)
+			} else {
+				fmt.Fprintf(w, "%v: %v", p.field, p.name)
+			}
+		}
+		fmt.Fprintf(w, "})\n\tfn := m.%vFunc\n\tm.mu.Unlock()\n", m.name)
+		args := make([]string, len(m.params))
+		for i, p := range m.params {
+			args[i] = p.name
+			if p.variadic {
+				args[i] += "..."
+			}
+		}
+		if len(m.results) == 0 {
+			fmt.Fprintf(w, "\tif fn != nil {\n\t\tfn(%v)\n\t}\n}\n", strings.Join(args, ", "))
+		} else {
+			fmt.Fprintf(w, "\tif fn != nil {\n\t\treturn fn(%v)\n\t}\n", strings.Join(args, ", "))
+			fmt.Fprintf(w, "\treturn\n}\n")
+		}
+
+		// Programming fixed results
+		if len(m.results) > 0 {
+			fmt.Fprintf(w, "\n// %vReturns programs %v to return the given results.\n", m.name, m.name)
+			fmt.Fprintf(w, "func (m *%v) %vReturns(%v) {\n", mock, m.name, paramList(m.results, true))
+			fmt.Fprintf(w, "\tm.mu.Lock()\n\tdefer m.mu.Unlock()\n")
+			fmt.Fprintf(w, "\tm.%vFunc = func(%v) %v {\n", m.name, paramList(m.params, false), resultList(m.results, false))
+			names := make([]string, len(m.results))
+			for i, r := range m.results {
+				names[i] = r.name
+			}
+			fmt.Fprintf(w, "\t\treturn %v\n\t}\n}\n", strings.Join(names, ", "))
+		}
+
+		// Inspecting the recorded calls
+		fmt.Fprintf(w, "\n// %vCalls returns the recorded calls to %v, oldest first.\n", m.name, m.name)
+		fmt.Fprintf(w, "func (m *%v) %vCalls() []%v {\n", mock, m.name, call)
+		fmt.Fprintf(w, "\tm.mu.Lock()\n\tdefer m.mu.Unlock()\n\treturn append([]%v(nil), m.%v...)\n}\n", call, calls)
+		fmt.Fprintf(w, "\n// %vCallCount returns the number of recorded calls to %v.\n", m.name, m.name)
+		fmt.Fprintf(w, "func (m *%v) %vCallCount() int {\n", mock, m.name)
+		fmt.Fprintf(w, "\tm.mu.Lock()\n\tdefer m.mu.Unlock()\n\treturn len(m.%v)\n}\n", calls)
+	}
+	return nil
+}
+
+// params describes the variables of a signature's parameters or
+// results.  Unnamed variables, and those whose names or fields
+// would clash with a name in used, are named prefix followed by
+// their index, with underscores appended until it is unused.  The
+// names and fields chosen are added to used.
+func (g *generator) params(vars *types.Tuple, variadic bool, prefix string, used map[string]bool) []param {
+	list := make([]param, vars.Len())
+	free := func(name string) bool {
+		return name != "" && name != "_" && !used[name] && !used[upperFirst(name)]
+	}
+	use := func(p *param, name string) {
+		p.name, p.field = name, upperFirst(name)
+		used[p.name], used[p.field] = true, true
+	}
+	// Keep the names which are free first, so that the generated
+	// names are chosen around them
+	for i := range list {
+		if name := vars.At(i).Name(); free(name) {
+			use(&list[i], name)
+		}
+	}
+	for i := range list {
+		v := vars.At(i)
+		p := param{typ: types.TypeString(v.Type(), g.qualifier)}
+		if variadic && i == vars.Len()-1 {
+			p.variadic = true
+			p.typ = types.TypeString(v.Type().(*types.Slice).Elem(), g.qualifier)
+		}
+		if s, ok := v.Type().(*types.Slice); ok && !p.variadic {
+			if b, ok := s.Elem().(*types.Basic); ok && b.Kind() == types.Byte {
+				p.bytes = true
+			}
+		}
+		p.name, p.field = list[i].name, list[i].field
+		if p.name == "" {
+			name := prefix + strconv.Itoa(i)
+			for !free(name) {
+				name += "_"
+			}
+			use(&p, name)
+		}
+		list[i] = p
+	}
+	return list
+}
+
+// paramList formats params for a parameter list, with their names
+// if named is set.
+func paramList(params []param, named bool) string {
+	parts := make([]string, len(params))
+	for i, p := range params {
+		typ := p.typ
+		if p.variadic {
+			typ = "..." + typ
+		}
+		if named {
+			parts[i] = p.name + " " + typ
+		} else {
+			parts[i] = typ
+		}
+	}
+	return strings.Join(parts, ", ")
+}
+
+// resultList formats results for a result list.  Mocked methods
+// name their results, so that they can return the zero values with
+// a bare return.
+func resultList(results []param, named bool) string {
+	if len(results) == 0 {
+		return ""
+	}
+	return "(" + paramList(results, named) + ")"
+}
+
+func upperFirst(s string) string {
+	r := []rune(s)
+	r[0] = unicode.ToUpper(r[0])
+	return string(r)
+}
+
+// isStd reports whether path is in the standard library, whose
+// import paths have no dot in their first element.
+func isStd(path string) bool {
+	first, _, _ := strings.Cut(path, "/")
+	return !strings.Contains(first, ".")
+}
+
+func lastElem(path string) string {
+	return path[strings.LastIndex(path, "/")+1:]
+}
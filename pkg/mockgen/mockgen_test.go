@@ -0,0 +1,89 @@
+package mockgen_test
+
+import (
+	"bytes"
+	"flag"
+	"go/ast"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/whatsacomputertho/go-learn/pkg/mockgen"
+)
+
+var update = flag.Bool("update", false, "update the golden files")
+
+// load type-checks the package in testdata, with any extra files,
+// failing the test if it does not compile.
+func load(t *testing.T, extra ...*ast.File) *types.Package {
+	t.Helper()
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, filepath.Join("testdata", "mocked.go"), nil, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	conf := types.Config{Importer: importer.ForCompiler(fset, "source", nil)}
+	pkg, err := conf.Check("mocked", fset, append([]*ast.File{f}, extra...), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return pkg
+}
+
+func TestGenerateGolden(t *testing.T) {
+	src, err := mockgen.Generate(load(t), []string{"Store", "Notifier"}, "mocked", "mockgen_test")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	golden := filepath.Join("testdata", "mocked_mocks.golden")
+	if *update {
+		if err := os.WriteFile(golden, src, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	want, err := os.ReadFile(golden)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(src, want) {
+		t.Errorf("generated code differs from %v, run go test -update to update it\n%s", golden, src)
+	}
+
+	// The mocks must compile alongside the interfaces
+	f, err := parser.ParseFile(token.NewFileSet(), "mocked_mocks.go", src, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	load(t, f)
+}
+
+func TestGenerateErrors(t *testing.T) {
+	pkg := load(t)
+	tests := []struct {
+		name    string
+		iface   string
+		pkgName string
+		want    string
+	}{
+		{"Missing", "Missing", "mocked", "no interface Missing"},
+		{"NotAType", "Helper", "mocked", "Helper is not a type"},
+		{"NotAnInterface", "Config", "mocked", "Config is not an interface"},
+		{"Generic", "Stack", "mocked", "Stack is generic"},
+		{"Constraint", "Number", "mocked", "Number is a constraint"},
+		{"UnexportedMethod", "Notifier", "mockedmock", "Notifier has unexported method flush"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := mockgen.Generate(pkg, []string{tt.iface}, tt.pkgName, "mockgen_test")
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("Generate(%v) = %v, want an error containing %q", tt.iface, err, tt.want)
+			}
+		})
+	}
+}
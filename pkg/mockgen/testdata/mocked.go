@@ -0,0 +1,43 @@
+package mocked
+
+import (
+	"context"
+	"io"
+)
+
+// Store exercises the naming of parameters and results.
+type Store interface {
+	// Unnamed parameters and results
+	Get(context.Context, string) ([]byte, error)
+	// A blank parameter whose generated name is taken
+	Put(_ string, arg0 []byte) error
+	// Names whose fields would clash
+	Rename(a, A string)
+	// Names used by the generated code, and a parameter named
+	// like an unnamed result
+	Copy(m io.Writer, fn io.Reader, r0 int) (int64, error)
+	// Variadic parameters are recorded as slices
+	Delete(keys ...string) int
+	// No parameters or results
+	Reset()
+}
+
+// Notifier has an unexported method, so it can only be mocked in
+// this package.
+type Notifier interface {
+	Notify(msg string)
+	flush()
+}
+
+type Stack[T any] interface {
+	Push(T)
+}
+
+type Number interface {
+	~int | ~float64
+}
+
+type Config struct{}
+
+// Helper is a func, which cannot be mocked.
+func Helper() {}
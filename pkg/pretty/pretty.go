@@ -0,0 +1,360 @@
+/*
+Package pretty prints any value as an indented tree, showing the
+type of every part of it.
+
+The control-flow and interfaces lessons finish with a type
+switch over a handful of types such as int, float64 and string.
+This package carries the same idea through to every kind of
+value, by switching on the value's dynamic type where it has a
+familiar one, such as time.Time, and falling back to reflection
+on its kind everywhere else.  Structs, including their
+unexported and embedded fields, maps, slices, arrays, pointers,
+interfaces, channels and funcs are all printed.
+
+Every pointer is labelled with a number, such as &1, the first
+time it is printed.  A pointer which leads back to a value
+already being printed is shown as a cycle, as in <cycle &1>,
+rather than being followed forever.
+*/
+package pretty
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"reflect"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// A Printer prints values.  The zero value is ready to use, and
+// prints the whole of every value, indented by two spaces.
+type Printer struct {
+	Indent   string // Indentation per level, two spaces if empty
+	MaxDepth int    // Levels of nesting printed, 0 for no limit
+	MaxItems int    // Elements printed per slice, array or map, 0 for no limit
+}
+
+var defaultPrinter Printer
+
+// Print prints v to standard output.
+func Print(v any) error {
+	return defaultPrinter.Fprint(os.Stdout, v)
+}
+
+// Sprint returns v printed as a string.
+func Sprint(v any) string {
+	return defaultPrinter.Sprint(v)
+}
+
+// Fprint prints v to w.
+func Fprint(w io.Writer, v any) error {
+	return defaultPrinter.Fprint(w, v)
+}
+
+// Sprint returns v printed as a string, ending in a newline.
+func (p *Printer) Sprint(v any) string {
+	s := p.newState()
+	s.value(reflect.ValueOf(v), 0)
+	s.buf.WriteByte('\n')
+	return s.buf.String()
+}
+
+// Fprint prints v to w, followed by a newline.
+func (p *Printer) Fprint(w io.Writer, v any) error {
+	_, err := io.WriteString(w, p.Sprint(v))
+	return err
+}
+
+// ref identifies a value which other values may refer to, so that
+// cycles can be found.  The type is needed as well as the address,
+// since a struct and its first field share an address.
+type ref struct {
+	addr uintptr
+	typ  reflect.Type
+}
+
+// state holds the output and bookkeeping of a single print.
+type state struct {
+	p      *Printer
+	indent string
+	buf    bytes.Buffer
+	ids    map[ref]int  // Labels given to pointers
+	path   map[ref]bool // Values currently being printed
+}
+
+func (p *Printer) newState() *state {
+	indent := p.Indent
+	if indent == "" {
+		indent = "  "
+	}
+	return &state{p: p, indent: indent, ids: make(map[ref]int), path: make(map[ref]bool)}
+}
+
+// line starts a new line indented to depth.
+func (s *state) line(depth int) {
+	s.buf.WriteByte('\n')
+	s.buf.WriteString(strings.Repeat(s.indent, depth))
+}
+
+// value prints v, whose first line has already been indented to
+// depth.
+func (s *state) value(v reflect.Value, depth int) {
+	if !v.IsValid() {
+		s.buf.WriteString("nil")
+		return
+	}
+	t := v.Type()
+	if text, ok := leaf(v); ok {
+		fmt.Fprintf(&s.buf, "%v %v", t, text)
+		return
+	}
+
+	switch v.Kind() {
+	case reflect.Pointer:
+		if v.IsNil() {
+			fmt.Fprintf(&s.buf, "%v nil", t)
+			return
+		}
+		r := ref{v.Pointer(), t}
+		id, seen := s.ids[r]
+		if !seen {
+			id = len(s.ids) + 1
+			s.ids[r] = id
+		}
+		if s.path[r] {
+			fmt.Fprintf(&s.buf, "%v <cycle &%v>", t, id)
+			return
+		}
+		fmt.Fprintf(&s.buf, "&%v ", id)
+		s.path[r] = true
+		s.value(v.Elem(), depth)
+		delete(s.path, r)
+
+	case reflect.Interface:
+		// Print the dynamic value, which carries its own type
+		if v.IsNil() {
+			fmt.Fprintf(&s.buf, "%v nil", t)
+			return
+		}
+		s.value(v.Elem(), depth)
+
+	case reflect.Struct:
+		if t.NumField() == 0 {
+			fmt.Fprintf(&s.buf, "%v {}", t)
+			return
+		}
+		if s.tooDeep(t, depth) {
+			return
+		}
+		fmt.Fprintf(&s.buf, "%v {", t)
+		for i := 0; i < t.NumField(); i++ {
+			f := t.Field(i)
+			s.line(depth + 1)
+			s.buf.WriteString(f.Name)
+			if f.Anonymous {
+				s.buf.WriteString(" (embedded)")
+			}
+			s.buf.WriteString(": ")
+			s.value(v.Field(i), depth+1)
+		}
+		s.line(depth)
+		s.buf.WriteByte('}')
+
+	case reflect.Slice, reflect.Array:
+		if v.Kind() == reflect.Slice && v.IsNil() {
+			fmt.Fprintf(&s.buf, "%v nil", t)
+			return
+		}
+		if v.Len() == 0 {
+			fmt.Fprintf(&s.buf, "%v []", t)
+			return
+		}
+		if v.Kind() == reflect.Slice {
+			r := ref{v.Pointer(), t}
+			if s.path[r] {
+				fmt.Fprintf(&s.buf, "%v <cycle>", t)
+				return
+			}
+			s.path[r] = true
+			defer delete(s.path, r)
+		}
+		if s.tooDeep(t, depth) {
+			return
+		}
+		fmt.Fprintf(&s.buf, "%v (len %v) [", t, v.Len())
+		n := s.items(v.Len())
+		for i := 0; i < n; i++ {
+			s.line(depth + 1)
+			fmt.Fprintf(&s.buf, "%v: ", i)
+			s.value(v.Index(i), depth+1)
+		}
+		s.more(v.Len()-n, depth+1)
+		s.line(depth)
+		s.buf.WriteByte(']')
+
+	case reflect.Map:
+		if v.IsNil() {
+			fmt.Fprintf(&s.buf, "%v nil", t)
+			return
+		}
+		if v.Len() == 0 {
+			fmt.Fprintf(&s.buf, "%v {}", t)
+			return
+		}
+		r := ref{v.Pointer(), t}
+		if s.path[r] {
+			fmt.Fprintf(&s.buf, "%v <cycle>", t)
+			return
+		}
+		s.path[r] = true
+		defer delete(s.path, r)
+		if s.tooDeep(t, depth) {
+			return
+		}
+		fmt.Fprintf(&s.buf, "%v (len %v) {", t, v.Len())
+		keys := sortedKeys(v)
+		n := s.items(len(keys))
+		for _, k := range keys[:n] {
+			s.line(depth + 1)
+			fmt.Fprintf(&s.buf, "%v: ", key(k))
+			s.value(v.MapIndex(k), depth+1)
+		}
+		s.more(len(keys)-n, depth+1)
+		s.line(depth)
+		s.buf.WriteByte('}')
+
+	case reflect.Chan:
+		if v.IsNil() {
+			fmt.Fprintf(&s.buf, "%v nil", t)
+			return
+		}
+		fmt.Fprintf(&s.buf, "%v (len %v, cap %v)", t, v.Len(), v.Cap())
+
+	case reflect.Func:
+		if v.IsNil() {
+			fmt.Fprintf(&s.buf, "%v nil", t)
+			return
+		}
+		name := "?"
+		if fn := runtime.FuncForPC(v.Pointer()); fn != nil {
+			name = fn.Name()
+		}
+		fmt.Fprintf(&s.buf, "%v %v", t, name)
+
+	default:
+		fmt.Fprintf(&s.buf, "%v %#x", t, v.Pointer())
+	}
+}
+
+// tooDeep reports whether a value of type t at depth is nested past
+// the printer's MaxDepth, in which case it prints a placeholder.
+func (s *state) tooDeep(t reflect.Type, depth int) bool {
+	if s.p.MaxDepth <= 0 || depth < s.p.MaxDepth {
+		return false
+	}
+	if k := t.Kind(); k == reflect.Slice || k == reflect.Array {
+		fmt.Fprintf(&s.buf, "%v [...]", t)
+	} else {
+		fmt.Fprintf(&s.buf, "%v {...}", t)
+	}
+	return true
+}
+
+// items returns how many of n elements the printer prints.
+func (s *state) items(n int) int {
+	if s.p.MaxItems > 0 {
+		return min(n, s.p.MaxItems)
+	}
+	return n
+}
+
+// more notes the number of elements left out, if any.
+func (s *state) more(n, depth int) {
+	if n > 0 {
+		s.line(depth)
+		fmt.Fprintf(&s.buf, "... %v more", n)
+	}
+}
+
+var durationType = reflect.TypeOf(time.Duration(0))
+
+// leaf formats v on a single line if it is a value without parts,
+// such as a number or a string, or a type with a familiar format.
+func leaf(v reflect.Value) (string, bool) {
+	if v.Kind() != reflect.Interface && v.CanInterface() {
+		switch x := v.Interface().(type) {
+		case time.Time:
+			return x.Format(time.RFC3339Nano), true
+		case time.Duration:
+			return x.String(), true
+		case []byte:
+			if x == nil {
+				return "nil", true
+			}
+			return strconv.Quote(string(x)), true
+		}
+	}
+	// Values reached through unexported fields cannot be converted
+	// back to an interface, but a duration is still an int64
+	if v.Type() == durationType {
+		return time.Duration(v.Int()).String(), true
+	}
+
+	switch v.Kind() {
+	case reflect.Bool:
+		return strconv.FormatBool(v.Bool()), true
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(v.Int(), 10), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return strconv.FormatUint(v.Uint(), 10), true
+	case reflect.Uintptr:
+		return fmt.Sprintf("%#x", v.Uint()), true
+	case reflect.Float32, reflect.Float64:
+		return strconv.FormatFloat(v.Float(), 'g', -1, v.Type().Bits()), true
+	case reflect.Complex64, reflect.Complex128:
+		return strconv.FormatComplex(v.Complex(), 'g', -1, v.Type().Bits()), true
+	case reflect.String:
+		return strconv.Quote(v.String()), true
+	}
+	return "", false
+}
+
+// key formats a map key on a single line.
+func key(k reflect.Value) string {
+	if text, ok := leaf(k); ok {
+		return text
+	}
+	if k.CanInterface() {
+		return fmt.Sprintf("%+v", k.Interface())
+	}
+	return fmt.Sprintf("<%v>", k.Type())
+}
+
+// sortedKeys returns the keys of the map m in order, numerically
+// for numbers and by their printed form otherwise, so that the
+// output does not depend on the order of map iteration.
+func sortedKeys(m reflect.Value) []reflect.Value {
+	keys := m.MapKeys()
+	sort.Slice(keys, func(i, j int) bool {
+		a, b := keys[i], keys[j]
+		if a.Kind() == b.Kind() {
+			switch a.Kind() {
+			case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+				return a.Int() < b.Int()
+			case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+				return a.Uint() < b.Uint()
+			case reflect.Float32, reflect.Float64:
+				return a.Float() < b.Float()
+			case reflect.String:
+				return a.String() < b.String()
+			}
+		}
+		return key(a) < key(b)
+	})
+	return keys
+}
@@ -0,0 +1,148 @@
+package pretty_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/whatsacomputertho/go-learn/pkg/pretty"
+)
+
+type Animal struct {
+	Name string
+}
+
+type Dog struct {
+	Animal
+	owner *Owner
+	Tags  []string
+}
+
+type Owner struct {
+	Name string
+	Dogs []*Dog
+}
+
+type Node struct {
+	Value int
+	Next  *Node
+}
+
+func TestSprint(t *testing.T) {
+	var nilOwner *Owner
+	loop := &Node{Value: 1}
+	loop.Next = &Node{Value: 2, Next: loop}
+	shared := &Node{Value: 3}
+	owner := &Owner{Name: "Sam"}
+	owner.Dogs = []*Dog{{Animal: Animal{"Rex"}, owner: owner}}
+	self := map[string]any{}
+	self["self"] = self
+
+	tests := []struct {
+		name string
+		v    any
+		want string
+	}{
+		{"Nil", nil, "nil"},
+		{"NilPointer", nilOwner, "*pretty_test.Owner nil"},
+		{"NilSlice", []int(nil), "[]int nil"},
+		{"NilMap", map[string]int(nil), "map[string]int nil"},
+		{"NilInterface", struct{ E error }{}, `struct { E error } {
+  E: error nil
+}`},
+		{"NilFunc", (func())(nil), "func() nil"},
+		{"Leaves", []any{true, 1.5, "s", []byte("b"), time.Second}, `[]interface {} (len 5) [
+  0: bool true
+  1: float64 1.5
+  2: string "s"
+  3: []uint8 "b"
+  4: time.Duration 1s
+]`},
+		{"Map", map[int]string{10: "ten", 2: "two", -1: "minus one"}, `map[int]string (len 3) {
+  -1: string "minus one"
+  2: string "two"
+  10: string "ten"
+}`},
+		{"EmptyMap", map[string]int{}, "map[string]int {}"},
+		{"NestedStruct", Dog{Animal: Animal{"Rex"}, Tags: []string{"good"}}, `pretty_test.Dog {
+  Animal (embedded): pretty_test.Animal {
+    Name: string "Rex"
+  }
+  owner: *pretty_test.Owner nil
+  Tags: []string (len 1) [
+    0: string "good"
+  ]
+}`},
+		{"PointerCycle", loop, `&1 pretty_test.Node {
+  Value: int 1
+  Next: &2 pretty_test.Node {
+    Value: int 2
+    Next: *pretty_test.Node <cycle &1>
+  }
+}`},
+		{"SharedPointer", [2]*Node{shared, shared}, `[2]*pretty_test.Node (len 2) [
+  0: &1 pretty_test.Node {
+    Value: int 3
+    Next: *pretty_test.Node nil
+  }
+  1: &1 pretty_test.Node {
+    Value: int 3
+    Next: *pretty_test.Node nil
+  }
+]`},
+		{"CycleThroughSlice", owner, `&1 pretty_test.Owner {
+  Name: string "Sam"
+  Dogs: []*pretty_test.Dog (len 1) [
+    0: &2 pretty_test.Dog {
+      Animal (embedded): pretty_test.Animal {
+        Name: string "Rex"
+      }
+      owner: *pretty_test.Owner <cycle &1>
+      Tags: []string nil
+    }
+  ]
+}`},
+		{"MapCycle", self, `map[string]interface {} (len 1) {
+  "self": map[string]interface {} <cycle>
+}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := pretty.Sprint(tt.v); got != tt.want+"\n" {
+				t.Errorf("Sprint() =\n%v\nwant\n%v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPrinterLimits(t *testing.T) {
+	v := map[string][]int{"a": {1, 2, 3}, "b": {4}, "c": nil}
+	tests := []struct {
+		name string
+		p    pretty.Printer
+		want string
+	}{
+		{"MaxDepth", pretty.Printer{MaxDepth: 1}, `map[string][]int (len 3) {
+  "a": []int [...]
+  "b": []int [...]
+  "c": []int nil
+}`},
+		{"MaxItems", pretty.Printer{MaxItems: 2, Indent: "\t"}, `map[string][]int (len 3) {
+	"a": []int (len 3) [
+		0: int 1
+		1: int 2
+		... 1 more
+	]
+	"b": []int (len 1) [
+		0: int 4
+	]
+	... 1 more
+}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.p.Sprint(v); got != tt.want+"\n" {
+				t.Errorf("Sprint() =\n%v\nwant\n%v", got, tt.want)
+			}
+		})
+	}
+}
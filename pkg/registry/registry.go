@@ -0,0 +1,279 @@
+/*
+Package registry constructs writers by name from configuration
+strings, so that a program can let its user choose where its
+output goes without knowing every kind of writer in advance.
+
+A kind of writer is made available by registering a Factory for
+it under a name, usually from the init function of the package
+which provides it, in the same way as database/sql drivers.
+Programs then import that package for its side effects alone.
+
+	import _ "github.com/whatsacomputertho/go-learn/pkg/registry/writers"
+
+	w, err := registry.New("buffered:size=16")
+
+A configuration string is a registered name, optionally followed
+by a colon and comma separated key=value parameters, as in
+"file:path=out.log,append=true".  Values may not contain commas.
+Each Factory is registered along with the keys of the parameters
+it accepts, and any other parameter is rejected before the
+Factory is called, so that a misspelled parameter cannot fail
+only after a file has been truncated.
+*/
+package registry
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/whatsacomputertho/go-learn/pkg/iox"
+)
+
+// A Factory constructs writers from the parameters of a
+// configuration string.
+type Factory interface {
+	New(params *Params) (iox.WriterCloser, error)
+}
+
+// FactoryFunc adapts an ordinary function to the Factory
+// interface.
+type FactoryFunc func(params *Params) (iox.WriterCloser, error)
+
+// New calls f(params).
+func (f FactoryFunc) New(params *Params) (iox.WriterCloser, error) {
+	return f(params)
+}
+
+// ErrUnknown is returned when a configuration string names a
+// writer which has not been registered.
+var ErrUnknown = errors.New("registry: unknown writer")
+
+// registration is a registered Factory and the parameter keys it
+// accepts.
+type registration struct {
+	factory Factory
+	keys    map[string]bool
+}
+
+var (
+	mu        sync.RWMutex
+	factories = make(map[string]registration)
+)
+
+// Register makes a Factory available under name.  The factory
+// accepts the parameters named by keys, and no others.  It panics
+// if name is empty or contains a colon, if factory is nil, or if a
+// Factory is already registered under name.
+func Register(name string, factory Factory, keys ...string) {
+	mu.Lock()
+	defer mu.Unlock()
+	if name == "" || strings.Contains(name, ":") {
+		panic(fmt.Sprintf("registry: invalid name %q", name))
+	}
+	if factory == nil {
+		panic("registry: Register factory is nil")
+	}
+	if _, dup := factories[name]; dup {
+		panic(fmt.Sprintf("registry: Register called twice for %q", name))
+	}
+	reg := registration{factory: factory, keys: make(map[string]bool)}
+	for _, key := range keys {
+		reg.keys[key] = true
+	}
+	factories[name] = reg
+}
+
+// Names returns the sorted names of the registered factories.
+func Names() []string {
+	mu.RLock()
+	defer mu.RUnlock()
+	names := make([]string, 0, len(factories))
+	for name := range factories {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// New constructs the writer described by the configuration string
+// spec.  It fails if spec names an unregistered writer, if it gives
+// a parameter which the factory does not accept, or if the factory
+// rejects the parameters.  The factory is only called once the
+// parameters have been checked.
+func New(spec string) (iox.WriterCloser, error) {
+	name, params, reg, err := lookup(spec)
+	if err != nil {
+		return nil, err
+	}
+	w, err := reg.factory.New(params)
+	if err != nil {
+		return nil, fmt.Errorf("registry: %v: %w", name, err)
+	}
+	return w, nil
+}
+
+// lookup parses spec, and checks that it names a registered
+// Factory and gives only parameters which the Factory accepts.
+func lookup(spec string) (string, *Params, registration, error) {
+	name, params, err := Parse(spec)
+	if err != nil {
+		return "", nil, registration{}, err
+	}
+	mu.RLock()
+	reg, ok := factories[name]
+	mu.RUnlock()
+	if !ok {
+		return "", nil, registration{}, fmt.Errorf("%w %q, want one of %v", ErrUnknown, name, strings.Join(Names(), ", "))
+	}
+
+	var unknown []string
+	for key := range params.values {
+		if !reg.keys[key] {
+			unknown = append(unknown, key)
+		}
+	}
+	if len(unknown) > 0 {
+		sort.Strings(unknown)
+		return "", nil, registration{}, fmt.Errorf("registry: %v: unknown parameter(s) %v", name, strings.Join(unknown, ", "))
+	}
+	return name, params, reg, nil
+}
+
+// Parse splits the configuration string spec into a name and its
+// parameters.
+func Parse(spec string) (string, *Params, error) {
+	name, rest, hasParams := strings.Cut(spec, ":")
+	if name == "" {
+		return "", nil, fmt.Errorf("registry: missing writer name in %q", spec)
+	}
+	params := &Params{values: make(map[string]string)}
+	if !hasParams {
+		return name, params, nil
+	}
+	for _, kv := range strings.Split(rest, ",") {
+		key, value, ok := strings.Cut(kv, "=")
+		if !ok || key == "" {
+			return "", nil, fmt.Errorf("registry: invalid parameter %q in %q, want key=value", kv, spec)
+		}
+		if _, dup := params.values[key]; dup {
+			return "", nil, fmt.Errorf("registry: duplicate parameter %q in %q", key, spec)
+		}
+		params.values[key] = value
+	}
+	return name, params, nil
+}
+
+// Params holds the parameters of a configuration string.
+type Params struct {
+	values map[string]string
+}
+
+// Lookup returns the value of the parameter key, and whether it
+// was given.
+func (p *Params) Lookup(key string) (string, bool) {
+	v, ok := p.values[key]
+	return v, ok
+}
+
+// String returns the value of the parameter key, or def if it was
+// not given.
+func (p *Params) String(key, def string) string {
+	if v, ok := p.Lookup(key); ok {
+		return v
+	}
+	return def
+}
+
+// Required returns the value of the parameter key, or an error if
+// it was not given.
+func (p *Params) Required(key string) (string, error) {
+	v, ok := p.Lookup(key)
+	if !ok || v == "" {
+		return "", fmt.Errorf("missing parameter %q", key)
+	}
+	return v, nil
+}
+
+// Int returns the value of the parameter key as an int, or def if
+// it was not given.
+func (p *Params) Int(key string, def int) (int, error) {
+	v, ok := p.Lookup(key)
+	if !ok {
+		return def, nil
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil {
+		return 0, fmt.Errorf("parameter %q: %w", key, err)
+	}
+	return n, nil
+}
+
+// Bool returns the value of the parameter key as a bool, or def if
+// it was not given.
+func (p *Params) Bool(key string, def bool) (bool, error) {
+	v, ok := p.Lookup(key)
+	if !ok {
+		return def, nil
+	}
+	b, err := strconv.ParseBool(v)
+	if err != nil {
+		return false, fmt.Errorf("parameter %q: %w", key, err)
+	}
+	return b, nil
+}
+
+// Duration returns the value of the parameter key as a
+// time.Duration, or def if it was not given.
+func (p *Params) Duration(key string, def time.Duration) (time.Duration, error) {
+	v, ok := p.Lookup(key)
+	if !ok {
+		return def, nil
+	}
+	d, err := time.ParseDuration(v)
+	if err != nil {
+		return 0, fmt.Errorf("parameter %q: %w", key, err)
+	}
+	return d, nil
+}
+
+// Flag is a flag.Value holding a configuration string, so that a
+// program can select a writer with a command line flag.  The name
+// and parameter keys are checked when the flag is set, but the
+// parameter values are only checked by New, as checking them means
+// constructing the writer.
+type Flag struct {
+	spec string
+}
+
+// NewFlag returns a Flag holding the configuration string def.
+func NewFlag(def string) *Flag {
+	return &Flag{spec: def}
+}
+
+// String returns the configuration string.
+func (f *Flag) String() string {
+	if f == nil {
+		return ""
+	}
+	return f.spec
+}
+
+// Set checks that spec is well formed, names a registered writer
+// and gives only parameters which it accepts, and stores it.
+func (f *Flag) Set(spec string) error {
+	if _, _, _, err := lookup(spec); err != nil {
+		return err
+	}
+	f.spec = spec
+	return nil
+}
+
+// New constructs the writer described by the configuration string.
+func (f *Flag) New() (iox.WriterCloser, error) {
+	return New(f.spec)
+}
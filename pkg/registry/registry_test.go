@@ -0,0 +1,174 @@
+package registry_test
+
+import (
+	"bytes"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/whatsacomputertho/go-learn/pkg/iox"
+	"github.com/whatsacomputertho/go-learn/pkg/registry"
+)
+
+// recorder is a Factory which records the parameters it was
+// called with.
+type recorder struct {
+	calls  int
+	params map[string]string
+}
+
+func (r *recorder) New(params *registry.Params) (iox.WriterCloser, error) {
+	r.calls++
+	r.params = make(map[string]string)
+	for _, key := range []string{"a", "b"} {
+		if v, ok := params.Lookup(key); ok {
+			r.params[key] = v
+		}
+	}
+	if params.String("fail", "") != "" {
+		return nil, errors.New("failed")
+	}
+	return iox.NopCloser(&bytes.Buffer{}), nil
+}
+
+var rec = &recorder{}
+
+func init() {
+	registry.Register("test-recorder", rec, "a", "b", "fail")
+}
+
+func TestNew(t *testing.T) {
+	tests := []struct {
+		spec    string
+		params  map[string]string // Parameters the factory saw, or nil if not called
+		wantErr string
+	}{
+		{"test-recorder", map[string]string{}, ""},
+		{"test-recorder:a=1,b=", map[string]string{"a": "1", "b": ""}, ""},
+		{"test-recorder:a=1,c=2,d=3", nil, "unknown parameter(s) c, d"},
+		{"test-recorder:fail=yes", map[string]string{}, "registry: test-recorder: failed"},
+		{"test-missing", nil, "unknown writer \"test-missing\""},
+		{"test-recorder:a", nil, "invalid parameter \"a\""},
+		{"test-recorder:a=1,a=2", nil, "duplicate parameter \"a\""},
+		{":a=1", nil, "missing writer name"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.spec, func(t *testing.T) {
+			*rec = recorder{}
+			w, err := registry.New(tt.spec)
+			if tt.wantErr == "" && err != nil {
+				t.Fatalf("New() = %v, want nil", err)
+			}
+			if tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)) {
+				t.Fatalf("New() = %v, want an error containing %q", err, tt.wantErr)
+			}
+			if err == nil {
+				w.Close()
+			}
+			if tt.params == nil {
+				if rec.calls != 0 {
+					t.Errorf("factory called with %v, want it not called", rec.params)
+				}
+			} else if !reflect.DeepEqual(rec.params, tt.params) {
+				t.Errorf("factory called with %v, want %v", rec.params, tt.params)
+			}
+		})
+	}
+}
+
+func TestNewUnknown(t *testing.T) {
+	if _, err := registry.New("test-missing"); !errors.Is(err, registry.ErrUnknown) {
+		t.Errorf("New() = %v, want %v", err, registry.ErrUnknown)
+	}
+}
+
+func TestRegisterPanics(t *testing.T) {
+	tests := []struct {
+		name    string
+		factory registry.Factory
+	}{
+		{"", rec},
+		{"test:colon", rec},
+		{"test-nil", nil},
+		{"test-recorder", rec},
+	}
+	for _, tt := range tests {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("Register(%q, %v) did not panic", tt.name, tt.factory)
+				}
+			}()
+			registry.Register(tt.name, tt.factory)
+		}()
+	}
+}
+
+func TestParams(t *testing.T) {
+	_, params, err := registry.Parse("x:s=str,i=42,b=true,d=1s,bad=x,empty=")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := params.String("s", "def"); got != "str" {
+		t.Errorf("String(s) = %q, want %q", got, "str")
+	}
+	if got := params.String("none", "def"); got != "def" {
+		t.Errorf("String(none) = %q, want %q", got, "def")
+	}
+	if got, err := params.Int("i", 0); got != 42 || err != nil {
+		t.Errorf("Int(i) = %v, %v, want 42, <nil>", got, err)
+	}
+	if got, err := params.Bool("b", false); !got || err != nil {
+		t.Errorf("Bool(b) = %v, %v, want true, <nil>", got, err)
+	}
+	if got, err := params.Duration("d", 0); got != time.Second || err != nil {
+		t.Errorf("Duration(d) = %v, %v, want 1s, <nil>", got, err)
+	}
+	if got, err := params.Int("none", 7); got != 7 || err != nil {
+		t.Errorf("Int(none) = %v, %v, want 7, <nil>", got, err)
+	}
+	if _, err := params.Int("bad", 0); err == nil {
+		t.Error("Int(bad) succeeded")
+	}
+	if _, err := params.Bool("bad", false); err == nil {
+		t.Error("Bool(bad) succeeded")
+	}
+	if _, err := params.Duration("bad", 0); err == nil {
+		t.Error("Duration(bad) succeeded")
+	}
+	for _, key := range []string{"none", "empty"} {
+		if _, err := params.Required(key); err == nil {
+			t.Errorf("Required(%v) succeeded", key)
+		}
+	}
+}
+
+func TestFlag(t *testing.T) {
+	tests := []struct {
+		spec string
+		ok   bool
+	}{
+		{"test-recorder:a=1", true},
+		// Values are only checked by New
+		{"test-recorder:fail=yes", true},
+		{"test-recorder:c=1", false},
+		{"test-missing", false},
+		{"", false},
+	}
+	for _, tt := range tests {
+		f := registry.NewFlag("test-recorder")
+		err := f.Set(tt.spec)
+		if (err == nil) != tt.ok {
+			t.Errorf("Set(%q) = %v, want success: %v", tt.spec, err, tt.ok)
+		}
+		want := "test-recorder"
+		if tt.ok {
+			want = tt.spec
+		}
+		if got := f.String(); got != want {
+			t.Errorf("after Set(%q), String() = %q, want %q", tt.spec, got, want)
+		}
+	}
+}
@@ -0,0 +1,131 @@
+/*
+Package writers registers the writers of the iox package with the
+registry package.  It is imported for its side effects alone.
+
+	import _ "github.com/whatsacomputertho/go-learn/pkg/registry/writers"
+
+The following writers are registered.
+
+	console              writes to standard output
+	  stream=stderr        write to standard error instead
+	buffered             buffers writes to standard output
+	  size=8               pass data on in chunks of this many bytes
+	  policy=chunks        or lines, or manual
+	  interval=1s          also flush this often
+	  stream=stderr        write to standard error instead
+	file                 writes to a file, which Close closes
+	  path=out.log         the file to write, required
+	  append=true          append rather than truncate
+	gzip                 compresses to a file in gzip format
+	  path=out.log.gz      the file to write, required
+	  append=true          append rather than truncate
+*/
+package writers
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/whatsacomputertho/go-learn/pkg/iox"
+	"github.com/whatsacomputertho/go-learn/pkg/registry"
+)
+
+func init() {
+	registry.Register("console", registry.FactoryFunc(newConsole), "stream")
+	registry.Register("buffered", registry.FactoryFunc(newBuffered), "size", "policy", "interval", "stream")
+	registry.Register("file", registry.FactoryFunc(newFile), "path", "append")
+	registry.Register("gzip", registry.FactoryFunc(newGzip), "path", "append")
+}
+
+// stream returns the standard stream named by the stream
+// parameter.
+func stream(params *registry.Params) (io.Writer, error) {
+	switch s := params.String("stream", "stdout"); s {
+	case "stdout":
+		return os.Stdout, nil
+	case "stderr":
+		return os.Stderr, nil
+	default:
+		return nil, fmt.Errorf("parameter \"stream\": unknown stream %q, want stdout or stderr", s)
+	}
+}
+
+func newConsole(params *registry.Params) (iox.WriterCloser, error) {
+	w, err := stream(params)
+	if err != nil {
+		return nil, err
+	}
+	return iox.NopCloser(iox.NewConsoleWriter(w)), nil
+}
+
+func newBuffered(params *registry.Params) (iox.WriterCloser, error) {
+	w, err := stream(params)
+	if err != nil {
+		return nil, err
+	}
+	size, err := params.Int("size", 8)
+	if err != nil {
+		return nil, err
+	}
+	if size < 1 {
+		return nil, fmt.Errorf("parameter \"size\": invalid size %v", size)
+	}
+	interval, err := params.Duration("interval", 0)
+	if err != nil {
+		return nil, err
+	}
+	if interval < 0 {
+		return nil, fmt.Errorf("parameter \"interval\": invalid interval %v", interval)
+	}
+
+	var opts []iox.Option
+	switch policy := params.String("policy", "chunks"); policy {
+	case "chunks":
+		opts = append(opts, iox.WithPolicy(iox.FlushChunks(size)))
+	case "lines":
+		opts = append(opts, iox.WithPolicy(iox.FlushLines()))
+	case "manual":
+		opts = append(opts, iox.WithPolicy(iox.FlushManual()))
+	default:
+		return nil, fmt.Errorf("parameter \"policy\": unknown policy %q, want chunks, lines or manual", policy)
+	}
+	if interval > 0 {
+		opts = append(opts, iox.WithFlushInterval(interval))
+	}
+	return iox.NewBufferedWriterCloser(w, opts...), nil
+}
+
+// openFile opens the file named by the path parameter for writing.
+func openFile(params *registry.Params) (*os.File, error) {
+	path, err := params.Required("path")
+	if err != nil {
+		return nil, err
+	}
+	appendTo, err := params.Bool("append", false)
+	if err != nil {
+		return nil, err
+	}
+	flags := os.O_WRONLY | os.O_CREATE | os.O_TRUNC
+	if appendTo {
+		flags = os.O_WRONLY | os.O_CREATE | os.O_APPEND
+	}
+	return os.OpenFile(path, flags, 0o644)
+}
+
+func newFile(params *registry.Params) (iox.WriterCloser, error) {
+	f, err := openFile(params)
+	if err != nil {
+		// Return a nil interface rather than a nil *os.File
+		return nil, err
+	}
+	return f, nil
+}
+
+func newGzip(params *registry.Params) (iox.WriterCloser, error) {
+	f, err := openFile(params)
+	if err != nil {
+		return nil, err
+	}
+	return iox.NewGzipWriter(f), nil
+}
@@ -0,0 +1,128 @@
+package writers_test
+
+import (
+	"compress/gzip"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/whatsacomputertho/go-learn/pkg/registry"
+	_ "github.com/whatsacomputertho/go-learn/pkg/registry/writers"
+)
+
+func TestRegistered(t *testing.T) {
+	names := strings.Join(registry.Names(), ",")
+	for _, name := range []string{"console", "buffered", "file", "gzip"} {
+		if !strings.Contains(","+names+",", ","+name+",") {
+			t.Errorf("%q not registered, have %v", name, names)
+		}
+	}
+}
+
+func TestFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "out.log")
+	write := func(spec, s string) {
+		t.Helper()
+		w, err := registry.New(spec)
+		if err != nil {
+			t.Fatal(err)
+		}
+		io.WriteString(w, s)
+		if err := w.Close(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	tests := []struct {
+		spec  string
+		write string
+		want  string
+	}{
+		{"file:path=" + path, "first\n", "first\n"},
+		{"file:append=true,path=" + path, "second\n", "first\nsecond\n"},
+		{"file:path=" + path, "third\n", "third\n"},
+	}
+	for _, tt := range tests {
+		write(tt.spec, tt.write)
+		if got, err := os.ReadFile(path); string(got) != tt.want || err != nil {
+			t.Errorf("after %v, file holds %q, %v, want %q", tt.spec, got, err, tt.want)
+		}
+	}
+}
+
+// TestBadParamsLeaveFile checks that a configuration string which
+// is rejected does not truncate or create the file it names.
+func TestBadParamsLeaveFile(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "out.log")
+	if err := os.WriteFile(path, []byte("keep\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	for _, spec := range []string{
+		"file:path=" + path + ",apend=true",
+		"gzip:path=" + path + ",level=9",
+		"file:path=" + path + ",append=maybe",
+	} {
+		if _, err := registry.New(spec); err == nil {
+			t.Errorf("New(%q) succeeded", spec)
+		}
+		if got, err := os.ReadFile(path); string(got) != "keep\n" || err != nil {
+			t.Errorf("after New(%q), file holds %q, %v, want %q", spec, got, err, "keep\n")
+		}
+	}
+
+	missing := filepath.Join(dir, "missing.log")
+	if _, err := registry.New("file:path=" + missing + ",apend=true"); err == nil {
+		t.Errorf("New() with a misspelled parameter succeeded")
+	}
+	if _, err := os.Stat(missing); !os.IsNotExist(err) {
+		t.Errorf("New() with a misspelled parameter created the file: %v", err)
+	}
+}
+
+func TestGzip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "out.log.gz")
+	w, err := registry.New("gzip:path=" + path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	io.WriteString(w, "Hello, gzip!\n")
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	zr, err := gzip.NewReader(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, err := io.ReadAll(zr); string(got) != "Hello, gzip!\n" || err != nil {
+		t.Errorf("decompressed %q, %v, want %q", got, err, "Hello, gzip!\n")
+	}
+}
+
+func TestParamErrors(t *testing.T) {
+	tests := []struct {
+		spec    string
+		wantErr string
+	}{
+		{"console:stream=stdin", "unknown stream"},
+		{"buffered:size=0", "invalid size"},
+		{"buffered:size=big", "parameter \"size\""},
+		{"buffered:interval=-1s", "invalid interval"},
+		{"buffered:policy=never", "unknown policy"},
+		{"file", "missing parameter \"path\""},
+		{"gzip:path=", "missing parameter \"path\""},
+	}
+	for _, tt := range tests {
+		_, err := registry.New(tt.spec)
+		if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+			t.Errorf("New(%q) = %v, want an error containing %q", tt.spec, err, tt.wantErr)
+		}
+	}
+}
@@ -0,0 +1,65 @@
+/*
+Package roundtrip encodes a value and decodes it again, and
+reports what was lost along the way.
+
+The maps-structs lesson tags its structs' fields with json and
+xml tags, which the encoding/json and encoding/xml packages read
+to name the fields they encode.  Those packages can only see
+exported fields, so a value which is encoded and decoded again
+may come back without some of its data.  A round trip shows
+exactly which fields did not survive.
+
+	result, err := roundtrip.JSON(myPerson)
+	if err != nil {
+		return err
+	}
+	fmt.Println(string(result.Encoded)) // {"name":"Joe"}
+	fmt.Print(result.Lost)              // birthDay: 21 != 0 ...
+*/
+package roundtrip
+
+import (
+	"encoding/json"
+	"encoding/xml"
+
+	"github.com/whatsacomputertho/go-learn/pkg/diff"
+)
+
+// A Result is the outcome of a round trip.
+type Result[T any] struct {
+	// Encoded is the value as it was encoded.
+	Encoded []byte
+
+	// Decoded is the value decoded from Encoded.
+	Decoded T
+
+	// Lost lists the differences between the original value and
+	// Decoded, which is empty if the value survived intact.
+	Lost diff.Report
+}
+
+// JSON round trips v through encoding/json.
+func JSON[T any](v T) (*Result[T], error) {
+	return Via(v, json.Marshal, json.Unmarshal)
+}
+
+// XML round trips v through encoding/xml.
+func XML[T any](v T) (*Result[T], error) {
+	return Via(v, xml.Marshal, xml.Unmarshal)
+}
+
+// Via round trips v through the given marshal and unmarshal
+// functions, which have the signatures of json.Marshal and
+// json.Unmarshal.  The value is decoded into a new zero T.
+func Via[T any](v T, marshal func(v any) ([]byte, error), unmarshal func(data []byte, v any) error) (*Result[T], error) {
+	encoded, err := marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	r := &Result[T]{Encoded: encoded}
+	if err := unmarshal(encoded, &r.Decoded); err != nil {
+		return nil, err
+	}
+	r.Lost = diff.Diff(v, r.Decoded)
+	return r, nil
+}
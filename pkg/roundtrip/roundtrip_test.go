@@ -0,0 +1,125 @@
+package roundtrip_test
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/whatsacomputertho/go-learn/pkg/roundtrip"
+)
+
+type Person struct {
+	Name     string   `json:"name" xml:"name"`
+	Tags     []string `json:"tags,omitempty" xml:"tag"`
+	birthDay int
+	Skipped  string `json:"-" xml:"-"`
+}
+
+func TestJSONAndXML(t *testing.T) {
+	p := Person{Name: "Joe", Tags: []string{"a", "b"}, birthDay: 21, Skipped: "x"}
+	tests := []struct {
+		name    string
+		trip    func(Person) (*roundtrip.Result[Person], error)
+		encoded string
+	}{
+		{"JSON", roundtrip.JSON[Person], `{"name":"Joe","tags":["a","b"]}`},
+		{"XML", roundtrip.XML[Person], `<Person><name>Joe</name><tag>a</tag><tag>b</tag></Person>`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, err := tt.trip(p)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := string(r.Encoded); got != tt.encoded {
+				t.Errorf("Encoded = %v, want %v", got, tt.encoded)
+			}
+			want := Person{Name: "Joe", Tags: []string{"a", "b"}}
+			if !reflect.DeepEqual(r.Decoded, want) {
+				t.Errorf("Decoded = %+v, want %+v", r.Decoded, want)
+			}
+
+			// The unexported and skipped fields are lost
+			var lost []string
+			for _, d := range r.Lost {
+				lost = append(lost, d.String())
+			}
+			if want := []string{"birthDay: 21 != 0", `Skipped: "x" != ""`}; !reflect.DeepEqual(lost, want) {
+				t.Errorf("Lost = %q, want %q", lost, want)
+			}
+		})
+	}
+}
+
+func TestIntact(t *testing.T) {
+	r, err := roundtrip.JSON(map[string][]int{"a": {1, 2}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !r.Lost.Equal() {
+		t.Errorf("Lost = %v, want no differences", r.Lost)
+	}
+
+	// An empty slice comes back nil when omitted
+	r2, err := roundtrip.JSON(Person{Name: "Joe", Tags: []string{}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(r2.Lost) != 1 || r2.Lost[0].Path != "Tags" {
+		t.Errorf("Lost = %v, want the empty Tags", r2.Lost)
+	}
+}
+
+func TestViaErrors(t *testing.T) {
+	errMarshal := errors.New("marshal failed")
+	errUnmarshal := errors.New("unmarshal failed")
+	unmarshalCalled := false
+	tests := []struct {
+		name      string
+		marshal   func(any) ([]byte, error)
+		unmarshal func([]byte, any) error
+		wantErr   error
+	}{
+		{"Marshal",
+			func(any) ([]byte, error) { return nil, errMarshal },
+			func([]byte, any) error { unmarshalCalled = true; return nil },
+			errMarshal},
+		{"Unmarshal",
+			json.Marshal,
+			func([]byte, any) error { return errUnmarshal },
+			errUnmarshal},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, err := roundtrip.Via(Person{Name: "Joe"}, tt.marshal, tt.unmarshal)
+			if r != nil || !errors.Is(err, tt.wantErr) {
+				t.Errorf("Via() = %v, %v, want nil, %v", r, err, tt.wantErr)
+			}
+		})
+	}
+	if unmarshalCalled {
+		t.Error("unmarshal called after marshal failed")
+	}
+
+	// Values the encoding cannot handle fail in the same way
+	if _, err := roundtrip.JSON(make(chan int)); err == nil {
+		t.Error("JSON() of a channel succeeded")
+	}
+}
+
+func TestViaDecodesIntoZero(t *testing.T) {
+	// Via decodes into a new zero T, so fields the encoding leaves
+	// out are lost rather than kept from the original
+	r, err := roundtrip.Via(Person{Name: "Joe", birthDay: 21},
+		func(any) ([]byte, error) { return []byte(`{}`), nil }, json.Unmarshal)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(r.Decoded, Person{}) {
+		t.Errorf("Decoded = %+v, want the zero Person", r.Decoded)
+	}
+	if len(r.Lost) != 2 {
+		t.Errorf("Lost = %v, want Name and birthDay", r.Lost)
+	}
+}
@@ -0,0 +1,239 @@
+/*
+Package spawn measures the cost of spawning large numbers of
+goroutines.
+
+The goroutines lesson claims that goroutines are cheap enough
+that it is not uncommon to see 10,000 to 100,000 of them
+running at a time, while OS threads are allocated ~1MB of RAM
+each.  This package backs that claim with numbers by spawning
+a configurable number of goroutines, optionally pinning each
+one to its own OS thread via runtime.LockOSThread, and
+reporting the memory, stack and latency cost of doing so.
+*/
+package spawn
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"runtime"
+	"runtime/debug"
+	"runtime/pprof"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// MaxLockedGoroutines is the largest number of OS-thread-locked
+// goroutines Run will spawn.  Every locked goroutine that blocks
+// forces the runtime to create a new OS thread, and the runtime
+// crashes the process once it exceeds its thread limit (10,000
+// by default, see runtime/debug.SetMaxThreads).
+const MaxLockedGoroutines = 5000
+
+// ErrTooManyThreads is returned by Run when a locked run would
+// exceed MaxLockedGoroutines.
+var ErrTooManyThreads = errors.New("spawn: too many OS-thread-locked goroutines")
+
+// Config describes a single measurement.
+type Config struct {
+	// N is the number of goroutines to spawn.
+	N int
+
+	// LockOSThread pins every spawned goroutine to its own OS
+	// thread, approximating the cost of thread-per-task designs.
+	LockOSThread bool
+
+	// StackDepth is the number of nested calls each goroutine
+	// makes before parking, which forces its stack to grow
+	// beyond the initial allocation.
+	StackDepth int
+}
+
+// Report holds the results of a measurement.  Memory figures are
+// deltas between the moment before the first goroutine is spawned
+// and the moment every goroutine has parked.
+type Report struct {
+	Config
+
+	SpawnLatency time.Duration // Time until every goroutine has parked
+	Goroutines   int           // Live goroutines while parked
+	Threads      int           // OS threads created during the run
+	StackInuse   int64         // Bytes of goroutine stack in use
+	HeapInuse    int64         // Bytes of heap in use
+	Sys          int64         // Bytes obtained from the OS by the runtime
+	RSS          int64         // Resident set size, 0 if unavailable
+	Virtual      int64         // Virtual memory size, 0 if unavailable
+}
+
+// PerGoroutineLatency is the average spawn latency of a single
+// goroutine.
+func (r Report) PerGoroutineLatency() time.Duration {
+	if r.N == 0 {
+		return 0
+	}
+	return r.SpawnLatency / time.Duration(r.N)
+}
+
+// PerGoroutineStack is the average number of stack bytes used by
+// a single goroutine.
+func (r Report) PerGoroutineStack() int64 {
+	if r.N == 0 {
+		return 0
+	}
+	return r.StackInuse / int64(r.N)
+}
+
+// PerGoroutineMemory is the average number of stack and heap bytes
+// in use by a single goroutine.  This does not account for OS thread
+// stacks, which the runtime does not track; compare RSS and Virtual
+// for those.
+func (r Report) PerGoroutineMemory() int64 {
+	if r.N == 0 {
+		return 0
+	}
+	return (r.StackInuse + r.HeapInuse) / int64(r.N)
+}
+
+// Fprint writes a human-readable summary of the report to w.
+func (r Report) Fprint(w io.Writer) {
+	mode := "goroutines"
+	if r.LockOSThread {
+		mode = "OS-thread-locked goroutines"
+	}
+	fmt.Fprintf(w, "%v %v (stack depth %v)\n", r.N, mode, r.StackDepth)
+	fmt.Fprintf(w, "  spawn latency:    %v total, %v per goroutine\n", r.SpawnLatency, r.PerGoroutineLatency())
+	fmt.Fprintf(w, "  live goroutines:  %v\n", r.Goroutines)
+	fmt.Fprintf(w, "  threads created:  %v\n", r.Threads)
+	fmt.Fprintf(w, "  stack in use:     %v total, %v per goroutine\n", FormatBytes(r.StackInuse), FormatBytes(r.PerGoroutineStack()))
+	fmt.Fprintf(w, "  heap in use:      %v\n", FormatBytes(r.HeapInuse))
+	fmt.Fprintf(w, "  runtime sys:      %v\n", FormatBytes(r.Sys))
+	if r.RSS > 0 {
+		fmt.Fprintf(w, "  resident memory:  %v\n", FormatBytes(r.RSS))
+	}
+	if r.Virtual > 0 {
+		fmt.Fprintf(w, "  virtual memory:   %v\n", FormatBytes(r.Virtual))
+	}
+	fmt.Fprintf(w, "  memory/goroutine: %v\n", FormatBytes(r.PerGoroutineMemory()))
+}
+
+// Run spawns cfg.N goroutines, waits for every one of them to grow
+// its stack and park, takes its measurements, then releases them
+// and waits for them to exit.
+func Run(cfg Config) (Report, error) {
+	if cfg.N < 0 || cfg.StackDepth < 0 {
+		return Report{}, fmt.Errorf("spawn: invalid config %+v", cfg)
+	}
+	if cfg.LockOSThread && cfg.N > MaxLockedGoroutines {
+		return Report{}, fmt.Errorf("%w: %v > %v", ErrTooManyThreads, cfg.N, MaxLockedGoroutines)
+	}
+
+	// Settle the heap and return freed memory to the OS so that
+	// previous runs do not pollute the baseline
+	debug.FreeOSMemory()
+	var before, after runtime.MemStats
+	runtime.ReadMemStats(&before)
+	virtualBefore, rssBefore := readStatm()
+	threads := pprof.Lookup("threadcreate")
+	threadsBefore := threads.Count()
+
+	var parked, done sync.WaitGroup
+	release := make(chan struct{})
+	parked.Add(cfg.N)
+	done.Add(cfg.N)
+
+	start := time.Now()
+	for i := 0; i < cfg.N; i++ {
+		go func() {
+			defer done.Done()
+			if cfg.LockOSThread {
+				runtime.LockOSThread()
+				defer runtime.UnlockOSThread()
+			}
+			grow(cfg.StackDepth, func() {
+				parked.Done()
+				<-release
+			})
+		}()
+	}
+	parked.Wait()
+	latency := time.Since(start)
+
+	runtime.ReadMemStats(&after)
+	report := Report{
+		Config:       cfg,
+		SpawnLatency: latency,
+		Goroutines:   runtime.NumGoroutine(),
+		Threads:      threads.Count() - threadsBefore,
+		StackInuse:   int64(after.StackInuse) - int64(before.StackInuse),
+		HeapInuse:    int64(after.HeapInuse) - int64(before.HeapInuse),
+		Sys:          int64(after.Sys) - int64(before.Sys),
+	}
+	if virtual, rss := readStatm(); rss > 0 {
+		report.Virtual = virtual - virtualBefore
+		report.RSS = rss - rssBefore
+	}
+
+	close(release)
+	done.Wait()
+	return report, nil
+}
+
+// grow recurses depth times before calling park, keeping a small
+// array on every frame so that the goroutine's stack must grow.
+//
+//go:noinline
+func grow(depth int, park func()) byte {
+	var frame [128]byte
+	if depth == 0 {
+		park()
+		return frame[0]
+	}
+	frame[depth%len(frame)] = byte(depth)
+	return grow(depth-1, park) + frame[depth%len(frame)]
+}
+
+// readStatm returns the virtual memory size and resident set size
+// of the current process in bytes, or zeroes on platforms without
+// /proc.  OS thread stacks are reserved in virtual memory up front
+// but only become resident as they are touched.
+func readStatm() (virtual, rss int64) {
+	data, err := os.ReadFile("/proc/self/statm")
+	if err != nil {
+		return 0, 0
+	}
+	fields := strings.Fields(string(data))
+	if len(fields) < 2 {
+		return 0, 0
+	}
+	size, err := strconv.ParseInt(fields[0], 10, 64)
+	if err != nil {
+		return 0, 0
+	}
+	resident, err := strconv.ParseInt(fields[1], 10, 64)
+	if err != nil {
+		return 0, 0
+	}
+	page := int64(os.Getpagesize())
+	return size * page, resident * page
+}
+
+// FormatBytes renders a byte count using binary units.
+func FormatBytes(n int64) string {
+	const unit = 1024
+	sign := ""
+	if n < 0 {
+		sign, n = "-", -n
+	}
+	if n < unit {
+		return fmt.Sprintf("%v%vB", sign, n)
+	}
+	div, exp := int64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%v%.1f%ciB", sign, float64(n)/float64(div), "KMGTPE"[exp])
+}
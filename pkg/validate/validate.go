@@ -0,0 +1,281 @@
+/*
+Package validate checks structs against constraints declared in
+their field tags, like those on the maps-structs lesson's Dog.
+
+	type Dog struct {
+		IsGood bool   `required:"false" default:"true" mustSetEqualTo:"true"`
+		Breed  string `required:"true" max:"100"`
+	}
+
+The following tags are understood.  Other tags are ignored.
+
+	default:"v"         if the field is its zero value, set it to v
+	required:"true"     the field must not be its zero value
+	min:"n", max:"n"    strings, slices, arrays and maps must have
+	                    a length, and numbers a value, within bounds
+	mustSetEqualTo:"v"  the field must equal v
+
+Defaults are applied by the defaults package before anything is
+checked, so a field with a default is never missing.  Note that
+a field which was explicitly set to its zero value is
+indistinguishable from one which was never set, and so is given
+its default too.
+
+Struct is given a pointer to a struct, so that defaults can be
+set.  It recurses into nested and embedded structs, pointers to
+structs, the values held by interfaces, and the elements of
+slices, arrays and maps, and reports every constraint which
+fails, each with the path of its field, such as Dogs[1].Breed.
+Each pointer and map is descended into once, so cyclic values
+are safe.  Unexported fields are skipped.
+*/
+package validate
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/whatsacomputertho/go-learn/pkg/defaults"
+)
+
+// ErrNotStructPointer is returned when Struct is given anything
+// other than a non-nil pointer to a struct.
+var ErrNotStructPointer = errors.New("validate: want a non-nil pointer to a struct")
+
+// A FieldError describes a constraint which a field failed, or a
+// tag which could not be interpreted.
+type FieldError struct {
+	Path  string // Path of the field from the struct given to Struct
+	Tag   string // Tag of the constraint which failed
+	Param string // Parameter of the tag
+	Msg   string
+}
+
+func (e *FieldError) Error() string {
+	return e.Path + ": " + e.Msg
+}
+
+// Errors aggregates every FieldError found by Struct.
+type Errors []*FieldError
+
+func (errs Errors) Error() string {
+	msgs := make([]string, len(errs))
+	for i, e := range errs {
+		msgs[i] = e.Error()
+	}
+	return strings.Join(msgs, "\n")
+}
+
+// Unwrap returns the individual errors, for errors.Is and
+// errors.As.
+func (errs Errors) Unwrap() []error {
+	list := make([]error, len(errs))
+	for i, e := range errs {
+		list[i] = e
+	}
+	return list
+}
+
+// Struct applies the defaults declared on the fields of the struct
+// which ptr points to, then checks their constraints.  If any fail,
+// it returns an Errors holding every failure.
+func Struct(ptr any) error {
+	v := reflect.ValueOf(ptr)
+	if v.Kind() != reflect.Pointer || v.IsNil() || v.Elem().Kind() != reflect.Struct {
+		return ErrNotStructPointer
+	}
+	w := &walker{seen: make(map[visit]bool)}
+	if joined, ok := defaults.Apply(ptr).(interface{ Unwrap() []error }); ok {
+		for _, err := range joined.Unwrap() {
+			var fe *defaults.FieldError
+			if errors.As(err, &fe) {
+				w.errs = append(w.errs, &FieldError{Path: fe.Path, Tag: "default", Param: fe.Default, Msg: fmt.Sprintf("invalid default %q: %v", fe.Default, fe.Err)})
+			}
+		}
+	}
+	w.walk(v, "")
+	if len(w.errs) > 0 {
+		return w.errs
+	}
+	return nil
+}
+
+// walker holds the state of a single call to Struct.
+type walker struct {
+	errs Errors
+	seen map[visit]bool // The pointers and maps already descended into
+}
+
+// visit identifies a pointer or map by its address and type, as a
+// struct and its first field share an address.
+type visit struct {
+	ptr uintptr
+	typ reflect.Type
+}
+
+// enter reports whether the pointer or map v has not been
+// descended into before, and marks it as having been.
+func (w *walker) enter(v reflect.Value) bool {
+	k := visit{v.Pointer(), v.Type()}
+	if w.seen[k] {
+		return false
+	}
+	w.seen[k] = true
+	return true
+}
+
+// walkStruct checks the fields of the struct v, whose path is
+// prefix.
+func (w *walker) walkStruct(v reflect.Value, prefix string) {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if !f.IsExported() {
+			continue
+		}
+		path := f.Name
+		if prefix != "" {
+			path = prefix + "." + f.Name
+		}
+		field := v.Field(i)
+		checkField(field, f.Tag, path, &w.errs)
+		w.walk(field, path)
+	}
+}
+
+// walk descends into v, validating any structs it holds.
+func (w *walker) walk(v reflect.Value, path string) {
+	switch v.Kind() {
+	case reflect.Struct:
+		if v.Type() == timeType {
+			return
+		}
+		w.walkStruct(v, path)
+	case reflect.Pointer:
+		if !v.IsNil() && w.enter(v) {
+			w.walk(v.Elem(), path)
+		}
+	case reflect.Interface:
+		if !v.IsNil() {
+			w.walk(v.Elem(), path)
+		}
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			w.walk(v.Index(i), fmt.Sprintf("%v[%v]", path, i))
+		}
+	case reflect.Map:
+		if v.IsNil() || !w.enter(v) {
+			return
+		}
+		keys := v.MapKeys()
+		sort.Slice(keys, func(i, j int) bool {
+			return fmt.Sprint(keys[i]) < fmt.Sprint(keys[j])
+		})
+		for _, k := range keys {
+			w.walk(v.MapIndex(k), fmt.Sprintf("%v[%v]", path, k))
+		}
+	}
+}
+
+var timeType = reflect.TypeOf(time.Time{})
+
+// checkField checks the constraints of the field v, as declared
+// by tag.
+func checkField(v reflect.Value, tag reflect.StructTag, path string, errs *Errors) {
+	fail := func(key, param, format string, args ...any) {
+		*errs = append(*errs, &FieldError{Path: path, Tag: key, Param: param, Msg: fmt.Sprintf(format, args...)})
+	}
+
+	if req, ok := tag.Lookup("required"); ok {
+		required, err := strconv.ParseBool(req)
+		switch {
+		case err != nil:
+			fail("required", req, "invalid required tag %q, want true or false", req)
+		case required && v.IsZero():
+			// Its other constraints would only repeat the news
+			fail("required", req, "is required")
+			return
+		}
+	}
+
+	for _, key := range []string{"min", "max"} {
+		param, ok := tag.Lookup(key)
+		if !ok {
+			continue
+		}
+		if msg, err := checkBound(v, key, param); err != nil {
+			fail(key, param, "invalid %v tag %q: %v", key, param, err)
+		} else if msg != "" {
+			fail(key, param, "%v", msg)
+		}
+	}
+
+	if want, ok := tag.Lookup("mustSetEqualTo"); ok {
+		expected := reflect.New(v.Type()).Elem()
+		if err := defaults.Set(expected, want); err != nil {
+			fail("mustSetEqualTo", want, "invalid mustSetEqualTo tag %q: %v", want, err)
+		} else if !reflect.DeepEqual(v.Interface(), expected.Interface()) {
+			fail("mustSetEqualTo", want, "must be set to %v, but is %v", want, v.Interface())
+		}
+	}
+}
+
+// checkBound checks v against a min or max bound.  It returns a
+// message describing the failure, if any, or an error if the bound
+// cannot be applied to v.
+func checkBound(v reflect.Value, key, param string) (string, error) {
+	var what string
+	var value, bound float64
+	switch v.Kind() {
+	case reflect.String, reflect.Slice, reflect.Array, reflect.Map:
+		n, err := strconv.Atoi(param)
+		if err != nil {
+			return "", err
+		}
+		what, value, bound = "length", float64(v.Len()), float64(n)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		b := reflect.New(v.Type()).Elem()
+		if err := defaults.Set(b, param); err != nil {
+			return "", err
+		}
+		what, value, bound = "value", toFloat(v), toFloat(b)
+	default:
+		return "", fmt.Errorf("cannot bound a %v", v.Type())
+	}
+
+	if key == "min" && value < bound {
+		return fmt.Sprintf("%v %v is less than the minimum %v", what, formatBound(v, value), param), nil
+	}
+	if key == "max" && value > bound {
+		return fmt.Sprintf("%v %v is more than the maximum %v", what, formatBound(v, value), param), nil
+	}
+	return "", nil
+}
+
+func toFloat(v reflect.Value) float64 {
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(v.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(v.Uint())
+	}
+	return v.Float()
+}
+
+// formatBound formats the length or value being bounded, using the
+// field's own format where it has one, such as a duration's.
+func formatBound(v reflect.Value, value float64) string {
+	if v.Type() == durationType {
+		return v.Interface().(time.Duration).String()
+	}
+	return strconv.FormatFloat(value, 'g', -1, 64)
+}
+
+var durationType = reflect.TypeOf(time.Duration(0))
@@ -0,0 +1,119 @@
+package validate_test
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/whatsacomputertho/go-learn/pkg/validate"
+)
+
+type Dog struct {
+	IsGood bool   `required:"false" default:"true" mustSetEqualTo:"true"`
+	Breed  string `required:"true" max:"10"`
+}
+
+type Kennel struct {
+	Dogs  []Dog         `min:"1"`
+	Staff uint          `min:"1" max:"5"`
+	Rest  time.Duration `max:"1h"`
+	Rooms map[string]*Dog
+}
+
+type Holder struct {
+	Pet any
+}
+
+type Node struct {
+	Name string `required:"true"`
+	Next *Node
+}
+
+type Bad struct {
+	Flag  int      `required:"maybe"`
+	Limit string   `max:"ten"`
+	Ch    chan int `min:"1"`
+	Must  int      `mustSetEqualTo:"one"`
+}
+
+// failures returns the path and tag of each failure in err.
+func failures(t *testing.T, err error) []string {
+	t.Helper()
+	if err == nil {
+		return nil
+	}
+	var errs validate.Errors
+	if !errors.As(err, &errs) {
+		t.Fatalf("Struct() = %T, want validate.Errors", err)
+	}
+	var list []string
+	for _, fe := range errs {
+		list = append(list, fe.Path+" "+fe.Tag)
+	}
+	return list
+}
+
+func TestStruct(t *testing.T) {
+	tests := []struct {
+		name string
+		ptr  any
+		want []string
+	}{
+		{"Valid", &Dog{Breed: "lab"}, nil},
+		{"Required", &Dog{}, []string{"Breed required"}},
+		{"DefaultSatisfiesMustSetEqualTo", &Dog{Breed: "lab", IsGood: false}, nil},
+		{"Nested", &Kennel{
+			Dogs:  []Dog{{Breed: "lab"}, {Breed: "labradoodle"}},
+			Staff: 6,
+			Rest:  2 * time.Hour,
+			Rooms: map[string]*Dog{"a": {}, "b": nil},
+		}, []string{"Dogs[1].Breed max", "Staff max", "Rest max", "Rooms[a].Breed required"}},
+		{"Min", &Kennel{}, []string{"Dogs min", "Staff min"}},
+		{"Interface", &Holder{Pet: Dog{}}, []string{"Pet.Breed required"}},
+		{"InterfacePointer", &Holder{Pet: &Dog{Breed: "lab"}}, nil},
+		{"InvalidTags", &Bad{}, []string{
+			"Flag required", "Limit max", "Ch min", "Must mustSetEqualTo",
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := failures(t, validate.Struct(tt.ptr))
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Struct() failed %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestStructDefaults(t *testing.T) {
+	h := &Holder{Pet: Dog{Breed: "lab"}}
+	if err := validate.Struct(h); err != nil {
+		t.Fatalf("Struct() = %v, want nil", err)
+	}
+	if want := (Dog{IsGood: true, Breed: "lab"}); h.Pet != want {
+		t.Errorf("Struct() set Pet to %+v, want %+v", h.Pet, want)
+	}
+}
+
+func TestStructCycle(t *testing.T) {
+	n := &Node{}
+	n.Next = n
+	if got, want := failures(t, validate.Struct(n)), []string{"Name required"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Struct() failed %q, want %q", got, want)
+	}
+
+	m := map[string]any{}
+	m["self"] = m
+	if err := validate.Struct(&Holder{Pet: m}); err != nil {
+		t.Errorf("Struct() = %v, want nil", err)
+	}
+}
+
+func TestStructNotStructPointer(t *testing.T) {
+	for _, ptr := range []any{nil, Dog{}, (*Dog)(nil), new(int)} {
+		if err := validate.Struct(ptr); err != validate.ErrNotStructPointer {
+			t.Errorf("Struct(%#v) = %v, want %v", ptr, err, validate.ErrNotStructPointer)
+		}
+	}
+}